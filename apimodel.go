@@ -7,6 +7,12 @@ type accessTokenReqBody struct {
 	AppSecret string `json:"app_secret"`
 }
 
+type accessTokenRespBody struct {
+	Code           int    `json:"code"`
+	AppAccessToken string `json:"app_access_token"`
+	Expire         int64  `json:"expire"`
+}
+
 type sendPrivateMessageReqBody struct {
 	EmployeeCode string          `json:"employee_code"`
 	Message      json.RawMessage `json:"message"`
@@ -17,6 +23,20 @@ type sendGroupMessageReqBody struct {
 	Message json.RawMessage `json:"message"`
 }
 
+type sendMessageRespBody struct {
+	Code      int    `json:"code"`
+	MessageID string `json:"message_id"`
+}
+
+type updateMessageReqBody struct {
+	MessageID string          `json:"message_id"`
+	Message   json.RawMessage `json:"message"`
+}
+
+type updateMessageRespBody struct {
+	Code int `json:"code"`
+}
+
 type getGroupIDsRespBody struct {
 	Code             int    `json:"code"`
 	NextCursor       string `json:"next_cursor"`
@@ -24,3 +44,36 @@ type getGroupIDsRespBody struct {
 		GroupIDs []string `json:"group_id"`
 	} `json:"joined_group_chats"`
 }
+
+type getGroupInfoRespBody struct {
+	Code  int `json:"code"`
+	Group struct {
+		GroupName      string        `json:"group_name"`
+		GroupSettings  GroupSettings `json:"group_settings"`
+		GroupUserTotal int           `json:"group_user_total"`
+	} `json:"group"`
+}
+
+type listGroupMembersRespBody struct {
+	Code       int        `json:"code"`
+	NextCursor string     `json:"next_cursor"`
+	Members    []Employee `json:"members"`
+}
+
+type getEmployeeCodesByEmailReqBody struct {
+	Emails []string `json:"emails"`
+}
+
+type getEmployeeCodesByMobileReqBody struct {
+	Mobiles []string `json:"mobiles"`
+}
+
+type getEmployeeCodesRespBody struct {
+	Code      int `json:"code"`
+	Employees []struct {
+		Email          string `json:"email"`
+		Mobile         string `json:"mobile"`
+		EmployeeCode   string `json:"employee_code"`
+		EmployeeStatus int    `json:"employee_status"`
+	} `json:"employees"`
+}
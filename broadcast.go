@@ -0,0 +1,144 @@
+package seatalkbot
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+)
+
+// defaultBroadcastConcurrency is the number of messages sent at once when BroadcastOptions.Concurrency is not set.
+const defaultBroadcastConcurrency = 5
+
+// Recipient is an employee or a group chat receiving a broadcast. It's created by EmployeeRecipient or GroupRecipient.
+type Recipient struct {
+	EmployeeCode string `json:"employee_code,omitempty"`
+	GroupID      string `json:"group_id,omitempty"`
+}
+
+// EmployeeRecipient returns a Recipient for the private chat with the employee.
+func EmployeeRecipient(employeeCode string) Recipient {
+	return Recipient{EmployeeCode: employeeCode}
+}
+
+// GroupRecipient returns a Recipient for the group chat.
+func GroupRecipient(groupID string) Recipient {
+	return Recipient{GroupID: groupID}
+}
+
+type BroadcastOptions struct {
+	// Concurrency is the maximum number of messages sent at once. It's 5 by default.
+	Concurrency int
+	// RateLimiter limits the rate of the broadcast, on top of the RateLimiter of the client. Optional.
+	RateLimiter *RateLimiter
+}
+
+// BroadcastResult is the outcome of sending the message to a recipient.
+type BroadcastResult struct {
+	Recipient Recipient
+	// MessageID is the id of the sent message, it's empty when Err is not nil.
+	MessageID string
+	// Err is the error of the last attempt, or the context error when the message is not sent because the broadcast
+	// is canceled.
+	Err error
+	// Attempts is the number of attempts to send the message, including the retries. The API calls refreshing the
+	// access token are not counted.
+	Attempts int
+}
+
+// BroadcastReport has a result for every recipient, in the same order as the recipients.
+type BroadcastReport struct {
+	Results []BroadcastResult
+}
+
+// Failed returns the results with an error.
+func (r BroadcastReport) Failed() []BroadcastResult {
+	var failed []BroadcastResult
+	for _, result := range r.Results {
+		if result.Err != nil {
+			failed = append(failed, result)
+		}
+	}
+
+	return failed
+}
+
+// Broadcast implements Client
+func (c *client) Broadcast(ctx context.Context, recipients []Recipient, message Message, opts BroadcastOptions) (BroadcastReport, error) {
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultBroadcastConcurrency
+	}
+
+	report := BroadcastReport{Results: make([]BroadcastResult, len(recipients))}
+	rawMessage := message.Message()
+
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, concurrency)
+
+	for i, recipient := range recipients {
+		report.Results[i].Recipient = recipient
+
+		select {
+		case <-ctx.Done():
+			report.Results[i].Err = ctx.Err()
+			continue
+		case slots <- struct{}{}:
+		}
+
+		// The slot might have been acquired after ctx is done, when both were ready.
+		if err := ctx.Err(); err != nil {
+			<-slots
+			report.Results[i].Err = err
+			continue
+		}
+
+		wg.Add(1)
+		go func(result *BroadcastResult) {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			result.MessageID, result.Attempts, result.Err = c.broadcastTo(ctx, result.Recipient, rawMessage, opts.RateLimiter)
+		}(&report.Results[i])
+	}
+
+	wg.Wait()
+
+	return report, ctx.Err()
+}
+
+// broadcastTo sends the message to the recipient and returns the number of attempts made.
+func (c *client) broadcastTo(ctx context.Context, recipient Recipient, message []byte, limiter *RateLimiter) (messageID string, attempts int, err error) {
+	if (recipient.EmployeeCode == "") == (recipient.GroupID == "") {
+		return "", 0, errors.New("exactly one of EmployeeCode/GroupID must be set")
+	}
+
+	if err := limiter.Wait(ctx, ""); err != nil {
+		return "", 0, err
+	}
+
+	ctx, counter := withAttemptCounter(ctx)
+
+	if recipient.EmployeeCode != "" {
+		messageID, err = c.sendPrivateMessage(ctx, recipient.EmployeeCode, message)
+	} else {
+		messageID, err = c.sendGroupMessage(ctx, recipient.GroupID, message)
+	}
+
+	return messageID, int(counter.Load()), err
+}
+
+type attemptCounterKey struct{}
+
+// withAttemptCounter returns a context counting the attempts of the API calls made with it.
+func withAttemptCounter(ctx context.Context) (context.Context, *atomic.Int32) {
+	counter := new(atomic.Int32)
+	return context.WithValue(ctx, attemptCounterKey{}, counter), counter
+}
+
+// countAttempt increments the attempt counter of the context, if any.
+func countAttempt(ctx context.Context) {
+	if counter, ok := ctx.Value(attemptCounterKey{}).(*atomic.Int32); ok {
+		counter.Add(1)
+	}
+}
@@ -0,0 +1,150 @@
+package seatalkbot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+func Test_client_Broadcast(t *testing.T) {
+	t.Parallel()
+	var issued atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		switch r.URL.Path {
+		case "/auth/app_access_token":
+			w.WriteHeader(http.StatusOK)
+			_, _ = fmt.Fprintf(w, `{"app_access_token":"t%d"}`, issued.Add(1))
+
+		case "/messaging/v2/single_chat":
+			w.WriteHeader(http.StatusOK)
+			switch {
+			case gjson.GetBytes(body, "employee_code").String() == "unknown":
+				_, _ = w.Write([]byte(`{"code":3000}`))
+				return
+			case gjson.GetBytes(body, "employee_code").String() == "rejected" && r.Header.Get("Authorization") == "Bearer t1":
+				_, _ = w.Write([]byte(`{"code":100}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"code":0,"message_id":"m-` + gjson.GetBytes(body, "employee_code").String() + `"}`))
+
+		case "/messaging/v2/group_chat":
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write([]byte(`{"code":0,"message_id":"m-` + gjson.GetBytes(body, "group_id").String() + `"}`))
+
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	c, err := NewClient(Config{
+		HTTPClient: &http.Client{},
+		Host:       server.URL,
+		AppID:      "app-id",
+		AppSecret:  "app-secret",
+	})
+	require.NoError(t, err)
+
+	recipients := []Recipient{
+		EmployeeRecipient("1"),
+		GroupRecipient("g1"),
+		EmployeeRecipient("unknown"),
+		{},
+		{EmployeeCode: "2", GroupID: "g2"},
+		EmployeeRecipient("rejected"),
+	}
+
+	t.Run("it should report the result of every recipient", func(t *testing.T) {
+		report, err := c.Broadcast(context.Background(), recipients, TextMessage("abc", ""), BroadcastOptions{Concurrency: 2})
+
+		require.NoError(t, err)
+		require.Len(t, report.Results, len(recipients))
+
+		assert.Equal(t, BroadcastResult{Recipient: EmployeeRecipient("1"), MessageID: "m-1", Attempts: 1}, report.Results[0])
+		assert.Equal(t, BroadcastResult{Recipient: GroupRecipient("g1"), MessageID: "m-g1", Attempts: 1}, report.Results[1])
+		assert.True(t, IsUserNotFound(report.Results[2].Err))
+		assert.Equal(t, 1, report.Results[2].Attempts)
+		assert.EqualError(t, report.Results[3].Err, "exactly one of EmployeeCode/GroupID must be set")
+		assert.Equal(t, 0, report.Results[3].Attempts)
+		assert.EqualError(t, report.Results[4].Err, "exactly one of EmployeeCode/GroupID must be set")
+		assert.Equal(t, 0, report.Results[4].Attempts)
+		assert.Equal(t, BroadcastResult{Recipient: EmployeeRecipient("rejected"), MessageID: "m-rejected", Attempts: 1}, report.Results[5],
+			"the access token refresh should not be counted as an attempt")
+		assert.Len(t, report.Failed(), 3)
+	})
+
+	t.Run("it should return the partial report when the context is canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		report, err := c.Broadcast(ctx, recipients, TextMessage("abc", ""), BroadcastOptions{})
+
+		require.True(t, errors.Is(err, context.Canceled))
+		require.Len(t, report.Results, len(recipients))
+		for i, result := range report.Results {
+			assert.Equal(t, recipients[i], result.Recipient)
+			assert.Error(t, result.Err)
+		}
+	})
+}
+
+func Test_client_Broadcast_canceledMidRun(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/auth/app_access_token":
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write([]byte(`{"app_access_token":"abc"}`))
+
+		case "/messaging/v2/group_chat":
+			body, _ := io.ReadAll(r.Body)
+			if gjson.GetBytes(body, "group_id").String() == "g2" {
+				cancel()
+			}
+
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write([]byte(`{"code":0,"message_id":"m-` + gjson.GetBytes(body, "group_id").String() + `"}`))
+
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	c, err := NewClient(Config{
+		HTTPClient: &http.Client{},
+		Host:       server.URL,
+		AppID:      "app-id",
+		AppSecret:  "app-secret",
+	})
+	require.NoError(t, err)
+	defer c.Close()
+
+	recipients := []Recipient{GroupRecipient("g1"), GroupRecipient("g2"), GroupRecipient("g3"), GroupRecipient("g4")}
+
+	report, err := c.Broadcast(ctx, recipients, TextMessage("abc", ""), BroadcastOptions{Concurrency: 1})
+
+	require.ErrorIs(t, err, context.Canceled)
+	require.Len(t, report.Results, len(recipients))
+	assert.Equal(t, BroadcastResult{Recipient: GroupRecipient("g1"), MessageID: "m-g1", Attempts: 1}, report.Results[0])
+	assert.Equal(t, GroupRecipient("g2"), report.Results[1].Recipient)
+	for _, result := range report.Results[2:] {
+		assert.ErrorIs(t, result.Err, context.Canceled)
+		assert.Zero(t, result.Attempts)
+	}
+}
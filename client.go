@@ -1,19 +1,16 @@
 package seatalkbot
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"io"
+	"log/slog"
 	"net/http"
 	"net/url"
-	"strconv"
+	"strings"
 	"time"
 
-	"github.com/tidwall/gjson"
-
 	"github.com/anandawira/seatalkbot/helper"
 )
 
@@ -22,6 +19,14 @@ const (
 	defaultHost = "https://openapi.seatalk.io"
 	// pageSize is the page size for each API call that uses pagination
 	pageSize = 50
+	// employeeLookupBatchSize is the maximum number of emails or mobiles in a single employee lookup API call.
+	employeeLookupBatchSize = 500
+	// defaultHTTPTimeout is the timeout of the *http.Client used when not set in the config.
+	defaultHTTPTimeout = 30 * time.Second
+	// initAttempts is the number of attempts to get the first access token in NewClient.
+	initAttempts = 3
+	// initRetryInterval is the interval between the attempts to get the first access token.
+	initRetryInterval = 1 * time.Second
 )
 
 // Client is a Seatalkbot API caller. Client must initialize access token and update it with a new one before expired.
@@ -33,15 +38,51 @@ type Client interface {
 
 	// GetGroupIDs get list of group ids joined by the bot.
 	GetGroupIDs(ctx context.Context) ([]string, error)
+	// IterateGroupIDs calls fn for every group id joined by the bot, fetching one page at a time instead of
+	// buffering all of them. It stops when fn returns false, when ctx is done or when an API call fails.
+	IterateGroupIDs(ctx context.Context, fn func(groupID string) bool) error
+	// GetGroupInfo get the name, member count and settings of a group joined by the bot.
+	GetGroupInfo(ctx context.Context, groupID string) (GroupInfo, error)
+	// ListGroupMembers get list of members of a group joined by the bot.
+	ListGroupMembers(ctx context.Context, groupID string) ([]Employee, error)
 	// SendGroupMessage send a message to a group by groupID.
 	SendGroupMessage(ctx context.Context, groupID string, message Message) (messageID string, err error)
+	// ReplyInThread send a message to a group by groupID, into the thread started by the message threadID.
+	ReplyInThread(ctx context.Context, groupID, threadID string, message Message) (messageID string, err error)
+
+	// Broadcast sends the message to every recipient, with at most opts.Concurrency messages sent at once.
+	// A failure to send to a recipient doesn't stop the broadcast, it's reported in the result of the recipient.
+	// When ctx is done, the recipients not yet sent to are reported with the context error, and the partial
+	// report is returned along with the context error.
+	Broadcast(ctx context.Context, recipients []Recipient, message Message, opts BroadcastOptions) (BroadcastReport, error)
+
+	// UpdateInteractiveMessage replaces the content of an interactive message previously sent by the bot.
+	UpdateInteractiveMessage(ctx context.Context, messageID string, message Message) error
+
+	// GetEmployeeCodesByEmail resolves the emails to employee codes. The result has the same order as the emails
+	// and reports the status of each email, an email that can't be resolved doesn't fail the whole call.
+	GetEmployeeCodesByEmail(ctx context.Context, emails []string) ([]EmployeeLookup, error)
+	// GetEmployeeCodesByMobile resolves the mobile numbers to employee codes. See GetEmployeeCodesByEmail.
+	GetEmployeeCodesByMobile(ctx context.Context, mobiles []string) ([]EmployeeLookup, error)
 
 	// UpdateAccessToken gets new access token by using the credentials and store it in the client.
 	UpdateAccessToken(ctx context.Context) error
 	// AccessToken gets the underlying access token inside the client.
-	// It might be used to implement your own API caller that's not yet supported by this library.
+	// It might be used to implement your own API caller that's not yet supported by this library, see Call.
 	AccessToken() string
 
+	// Call calls an API that's not yet supported by this library, e.g. Call(ctx, http.MethodPost,
+	// "/messaging/v2/single_chat", req, &resp). The path is relative to the host and should start with /. It goes
+	// through the same pipeline as the other methods: access token, rate limiter, retry policy, logging, metrics.
+	// For a GET API, req must be url.Values or nil, it's sent as the query. Otherwise, req is sent as the json body,
+	// and no body is sent when it's nil. The response body is decoded as json into resp, unless it's nil.
+	// It returns an *APIError when the code in the response body is not 0.
+	// Only the GET API calls are retried on an error that is ambiguous, see RetryPolicy.
+	Call(ctx context.Context, method, path string, req, resp any) error
+
+	// Health reports the status of the access token refreshes, e.g. for a readiness probe, see HealthHandler.
+	Health() Health
+
 	// Close stops the goroutines that auto refresh the access token. It is required to call
 	// this function before the object passes out of scope, as it will otherwise leak memory.
 	Close() error
@@ -53,211 +94,309 @@ type client struct {
 	appID      string
 	appSecret  string
 
-	accessToken string
-	stop        context.CancelFunc
+	retryPolicy RetryPolicy
+	rateLimiter *RateLimiter
+
+	logger  *slog.Logger
+	metrics Metrics
+	tracer  Tracer
+
+	tracedRecipientHashKey []byte
+
+	tokens *tokenManager
+	stop   context.CancelFunc
 }
 
 type Config struct {
-	// HTTPClient will be used for every HTTP calls made by the seatalkbot client.
+	// HTTPClient will be used for every HTTP calls made by the seatalkbot client. By default, it's an *http.Client
+	// with a 30 seconds timeout.
 	HTTPClient *http.Client
 	// Host is the url of the bot api. It's https://openapi.seatalk.io by default.
 	Host string
 	// AppID of the seatalk bot. It can be found in the app setting at the seatalk dashboard.
+	// It's mandatory unless the TokenSource is set.
 	AppID string
 	// AppSecret of the seatalk bot. It can be found in the app setting at the seatalk dashboard.
+	// It's mandatory unless the TokenSource is set.
 	AppSecret string
+	// TokenSource provides the access token. By default, the access token is fetched from seatalk by using
+	// the AppID and AppSecret.
+	TokenSource TokenSource
+	// RetryPolicy configures how the API calls failing with a transient error are retried.
+	// The API calls are not retried when it's nil, see DefaultRetryPolicy for the recommended policy.
+	RetryPolicy *RetryPolicy
+	// RateLimiter limits the rate of the API calls. It can be shared by the clients of the same app.
+	// By default, each client has its own RateLimiter with the DefaultRateLimiterConfig limits.
+	RateLimiter *RateLimiter
+	// DisableRateLimiter stops the client from limiting the rate of the API calls, the RateLimiter is ignored.
+	DisableRateLimiter bool
+	// TokenStore caches the access token provided by the TokenSource. It can be shared by the clients of the same
+	// app, even across processes, so they don't fetch a new access token each.
+	TokenStore TokenStore
+	// Logger logs the token refreshes, retries, rate limit waits and API calls. The secrets and access tokens are
+	// never logged. Nothing is logged when it's nil.
+	Logger *slog.Logger
+	// Metrics receives the measurements of the API calls and the access token refreshes. Optional.
+	Metrics Metrics
+	// Tracer starts a span for every message sent, every page of group ids and every access token refresh.
+	// Optional.
+	Tracer Tracer
+	// TracedRecipientHashKey, when not empty, replaces the employee codes and group ids in the span attributes by
+	// their HMAC-SHA256 with the key. The result is a pseudonym to correlate the spans of the same recipient, not an
+	// anonymisation: anyone knowing the key can tell which recipient it is. The key should be kept secret.
+	TracedRecipientHashKey []byte
+	// OnTokenRefreshError is called with the error and the updated Health every time the access token can't be
+	// refreshed. It's called synchronously by the refresh, so it should not block. Optional.
+	OnTokenRefreshError func(err error, health Health)
+	// DisableAutoRefresh stops the client from refreshing the access token in the background. The access token is
+	// refreshed by the API call finding it expired or rejected instead.
+	DisableAutoRefresh bool
+	// LazyInit skips fetching the access token in NewClient. It's fetched in the background instead, or by the first
+	// API call when DisableAutoRefresh is set.
+	LazyInit bool
 }
 
-// NewClient returns a Client with the provided *http.Client and bot credentials. It will initialize access token using
-// the credentials and automatically refresh the access token every 7000 seconds (expiration is 7200 seconds).
-// It is required to call Close() before the object passes out of scope, as it will otherwise leak memory.
-func NewClient(config Config) (Client, error) {
+// validate sets the default values of the config and checks the mandatory ones.
+func (config *Config) validate() error {
 	if config.HTTPClient == nil {
-		return nil, errors.New("http client should not be nil")
+		config.HTTPClient = &http.Client{Timeout: defaultHTTPTimeout}
 	}
-	if config.Host == "" {
-		config.Host = defaultHost
+
+	if config.Logger == nil {
+		config.Logger = slog.New(discardHandler{})
+	}
+	if config.Metrics == nil {
+		config.Metrics = noopMetrics{}
+	}
+	if config.Tracer == nil {
+		config.Tracer = noopTracer{}
 	}
 
-	ctx, cancel := context.WithCancel(context.Background())
+	switch {
+	case config.DisableRateLimiter:
+		config.RateLimiter = nil
+	case config.RateLimiter == nil:
+		config.RateLimiter = NewRateLimiter(DefaultRateLimiterConfig())
+	}
 
-	c := &client{
-		httpClient:  config.HTTPClient,
-		host:        config.Host,
-		appID:       config.AppID,
-		appSecret:   config.AppSecret,
-		accessToken: "",
-		stop:        cancel,
+	if config.Host == "" {
+		config.Host = defaultHost
 	}
+	config.Host = strings.TrimSuffix(config.Host, "/")
 
-	err := helper.RunWithRetry(
-		func() error {
-			return c.UpdateAccessToken(ctx)
-		},
-		3,
-		1*time.Second,
-	)
-	if err != nil {
-		return nil, fmt.Errorf("can't initialize access token, %w", err)
+	host, err := url.Parse(config.Host)
+	if err != nil || (host.Scheme != "http" && host.Scheme != "https") || host.Host == "" {
+		return fmt.Errorf("host should be an http or https url, got: %q", config.Host)
 	}
 
-	c.runAccessTokenScheduler(ctx)
+	if config.TokenSource == nil && (config.AppID == "" || config.AppSecret == "") {
+		return errors.New("app id and app secret should not be empty")
+	}
 
-	return c, nil
+	return nil
 }
 
-// SendPrivateMessage implements Client
-func (c *client) SendPrivateMessage(ctx context.Context, employeeCode string, message Message) error {
-	reqBody, err := json.Marshal(sendPrivateMessageReqBody{
-		EmployeeCode: employeeCode,
-		Message:      message.Message(),
-	})
+// NewClient returns a Client with the provided config and options, the options override the config. It will
+// initialize access token using the credentials and automatically refresh the access token before it expires
+// (expiration is 7200 seconds).
+// It is required to call Close() before the object passes out of scope, as it will otherwise leak memory.
+func NewClient(config Config, opts ...Option) (Client, error) {
+	return NewClientWithContext(context.Background(), config, opts...)
+}
 
-	if err != nil {
-		return err
+// NewClientWithContext is NewClient using ctx to initialize the access token. It returns the error of ctx when ctx is
+// done before the access token is initialized. ctx only bounds the initialization, it doesn't stop the client.
+func NewClientWithContext(ctx context.Context, config Config, opts ...Option) (Client, error) {
+	for _, opt := range opts {
+		opt(&config)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.host+"/messaging/v2/single_chat", bytes.NewReader(reqBody))
-	if err != nil {
-		return err
+	if err := config.validate(); err != nil {
+		return nil, err
 	}
 
-	req.Header.Set("Authorization", "Bearer "+c.accessToken)
-	req.Header.Set("Content-Type", "application/json")
+	c := &client{
+		httpClient:  config.HTTPClient,
+		host:        config.Host,
+		appID:       config.AppID,
+		appSecret:   config.AppSecret,
+		rateLimiter: config.RateLimiter,
+		logger:      config.Logger,
+		metrics:     config.Metrics,
+		tracer:      config.Tracer,
 
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return err
+		tracedRecipientHashKey: config.TracedRecipientHashKey,
+	}
+	if config.RetryPolicy != nil {
+		c.retryPolicy = *config.RetryPolicy
 	}
 
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("http response code not 200, got: %d", resp.StatusCode)
+	var tokenSource TokenSource = TokenSourceFunc(c.fetchAccessToken)
+	if config.TokenSource != nil {
+		tokenSource = config.TokenSource
+	}
+	if config.TokenStore != nil {
+		tokenSource = newCachedTokenSource(tokenSource, config.TokenStore, c.logger)
 	}
 
-	respBody, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return err
+	c.tokens = newTokenManager(tokenSource, c.logger, c.metrics, c.tracer, config.OnTokenRefreshError)
+
+	if !config.LazyInit {
+		if err := c.initAccessToken(ctx); err != nil {
+			return nil, fmt.Errorf("can't initialize access token, %w", err)
+		}
 	}
 
-	if code := gjson.Get(string(respBody), "code"); !code.Exists() || code.Int() != 0 {
-		return fmt.Errorf("code in response body is not exist or not 0, resp_body: %s", respBody)
+	if !config.DisableAutoRefresh {
+		schedulerCtx, cancel := context.WithCancel(context.Background())
+		c.stop = cancel
+		c.runAccessTokenScheduler(schedulerCtx)
 	}
 
-	return nil
+	return c, nil
 }
 
-// GetGroupIDs implements Client
-func (c *client) GetGroupIDs(ctx context.Context) ([]string, error) {
-	var groupIDs []string
-	var cursor string
+// initAccessToken gets the first access token, retrying up to initAttempts times.
+func (c *client) initAccessToken(ctx context.Context) error {
+	var err error
 
-	for {
-		ids, nextCursor, err := c.getGroupIDs(ctx, cursor)
-		if err != nil {
-			return nil, err
+	for attempt := 1; attempt <= initAttempts; attempt++ {
+		if err = c.UpdateAccessToken(ctx); err == nil {
+			return nil
 		}
 
-		groupIDs = append(groupIDs, ids...)
+		c.logger.WarnContext(ctx, "can't initialize access token", slog.Int("attempt", attempt), slog.Any("error", err))
 
-		if nextCursor == "" {
-			break
+		if attempt < initAttempts {
+			if err := helper.Sleep(ctx, initRetryInterval); err != nil {
+				return err
+			}
 		}
 	}
 
-	return groupIDs, nil
+	return err
 }
 
-// SendGroupMessage implements Client
-func (c *client) SendGroupMessage(ctx context.Context, groupID string, message Message) (messageID string, err error) {
-	reqBody, err := json.Marshal(sendGroupMessageReqBody{
-		GroupID: groupID,
-		Message: message.Message(),
-	})
-
-	if err != nil {
-		return "", err
-	}
+// SendPrivateMessage implements Client
+func (c *client) SendPrivateMessage(ctx context.Context, employeeCode string, message Message) error {
+	_, err := c.sendPrivateMessage(ctx, employeeCode, message.Message())
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.host+"/messaging/v2/group_chat", bytes.NewReader(reqBody))
-	if err != nil {
-		return "", err
-	}
+	return err
+}
 
-	req.Header.Set("Authorization", "Bearer "+c.accessToken)
-	req.Header.Set("Content-Type", "application/json")
+// GetGroupIDs implements Client
+func (c *client) GetGroupIDs(ctx context.Context) ([]string, error) {
+	var groupIDs []string
 
-	resp, err := c.httpClient.Do(req)
+	err := paginate(ctx, c.getGroupIDs, func(groupID string) bool {
+		groupIDs = append(groupIDs, groupID)
+		return true
+	})
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	defer resp.Body.Close()
+	return groupIDs, nil
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("http response code not 200, got: %d", resp.StatusCode)
-	}
+// IterateGroupIDs implements Client
+func (c *client) IterateGroupIDs(ctx context.Context, fn func(groupID string) bool) error {
+	return paginate(ctx, c.getGroupIDs, fn)
+}
 
-	respBody, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return "", err
-	}
+// GetGroupInfo implements Client
+func (c *client) GetGroupInfo(ctx context.Context, groupID string) (GroupInfo, error) {
+	q := url.Values{}
+	q.Set("group_id", groupID)
 
-	if code := gjson.Get(string(respBody), "code"); !code.Exists() || code.Int() != 0 {
-		return "", fmt.Errorf("code in response body is not exist or not 0, resp_body: %s", respBody)
+	response, err := do[url.Values, getGroupInfoRespBody](ctx, c, endpointGroupInfo, q)
+	if err != nil {
+		return GroupInfo{}, err
 	}
 
-	return gjson.Get(string(respBody), "message_id").String(), nil
+	return GroupInfo{
+		GroupID:     groupID,
+		GroupName:   response.Group.GroupName,
+		MemberCount: response.Group.GroupUserTotal,
+		Settings:    response.Group.GroupSettings,
+	}, nil
 }
 
-// UpdateAccessToken implements Client
-func (c *client) UpdateAccessToken(ctx context.Context) error {
-	reqBody, err := json.Marshal(accessTokenReqBody{
-		AppID:     c.appID,
-		AppSecret: c.appSecret,
-	})
+// ListGroupMembers implements Client
+func (c *client) ListGroupMembers(ctx context.Context, groupID string) ([]Employee, error) {
+	var members []Employee
 
-	if err != nil {
-		return err
+	fetch := func(ctx context.Context, cursor string) ([]Employee, string, error) {
+		return c.listGroupMembers(ctx, groupID, cursor)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.host+"/auth/app_access_token", bytes.NewReader(reqBody))
+	err := paginate(ctx, fetch, func(member Employee) bool {
+		members = append(members, member)
+		return true
+	})
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	req.Header.Set("Content-Type", "application/json")
-
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return err
-	}
+	return members, nil
+}
 
-	defer resp.Body.Close()
+// SendGroupMessage implements Client
+func (c *client) SendGroupMessage(ctx context.Context, groupID string, message Message) (messageID string, err error) {
+	return c.sendGroupMessage(ctx, groupID, message.Message())
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("status code is not 200, got: %d", resp.StatusCode)
+// ReplyInThread implements Client
+func (c *client) ReplyInThread(ctx context.Context, groupID, threadID string, message Message) (messageID string, err error) {
+	if threadID == "" {
+		return "", errors.New("thread id should not be empty")
 	}
 
-	respBody, err := io.ReadAll(resp.Body)
+	rawMessage, err := withThreadID(message.Message(), threadID)
 	if err != nil {
-		return err
+		return "", err
 	}
 
-	accessToken := gjson.Get(string(respBody), "app_access_token")
-	if !accessToken.Exists() {
-		return fmt.Errorf("access token not exist. resp_body: %s", respBody)
-	}
+	return c.sendGroupMessage(ctx, groupID, rawMessage)
+}
 
-	c.accessToken = accessToken.String()
+// UpdateInteractiveMessage implements Client
+func (c *client) UpdateInteractiveMessage(ctx context.Context, messageID string, message Message) error {
+	_, err := do[updateMessageReqBody, updateMessageRespBody](ctx, c, endpointUpdateMessage, updateMessageReqBody{
+		MessageID: messageID,
+		Message:   message.Message(),
+	})
 
-	return nil
+	return err
+}
+
+// GetEmployeeCodesByEmail implements Client
+func (c *client) GetEmployeeCodesByEmail(ctx context.Context, emails []string) ([]EmployeeLookup, error) {
+	return c.getEmployeeCodes(ctx, emails, endpointEmployeeCodeByEmail, func(batch []string) any {
+		return getEmployeeCodesByEmailReqBody{Emails: batch}
+	})
+}
+
+// GetEmployeeCodesByMobile implements Client
+func (c *client) GetEmployeeCodesByMobile(ctx context.Context, mobiles []string) ([]EmployeeLookup, error) {
+	return c.getEmployeeCodes(ctx, mobiles, endpointEmployeeCodeByMobile, func(batch []string) any {
+		return getEmployeeCodesByMobileReqBody{Mobiles: batch}
+	})
+}
+
+// UpdateAccessToken implements Client
+func (c *client) UpdateAccessToken(ctx context.Context) error {
+	return c.tokens.refresh(ctx)
 }
 
 // AccessToken implements Client
 func (c *client) AccessToken() string {
-	return c.accessToken
+	return c.tokens.token()
+}
+
+// Health implements Client
+func (c *client) Health() Health {
+	return c.tokens.health()
 }
 
 // Close implements Client
@@ -268,68 +407,158 @@ func (c *client) Close() error {
 	return nil
 }
 
-func (c *client) getGroupIDs(ctx context.Context, cursor string) (groupIDs []string, nextCursor string, err error) {
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.host+"/messaging/v2/group_chat/joined", http.NoBody)
+func (c *client) sendPrivateMessage(ctx context.Context, employeeCode string, message json.RawMessage) (messageID string, err error) {
+	ctx = withRecipient(ctx, EmployeeRecipient(employeeCode))
+	ctx, span := c.startSpan(ctx, "seatalkbot.SendPrivateMessage", endpointSingleChat.path, EmployeeRecipient(employeeCode))
+	defer func() { endSpan(span, err) }()
+
+	response, err := do[sendPrivateMessageReqBody, sendMessageRespBody](ctx, c, endpointSingleChat, sendPrivateMessageReqBody{
+		EmployeeCode: employeeCode,
+		Message:      message,
+	})
 	if err != nil {
-		return nil, "", err
+		return "", err
 	}
 
-	req.Header.Set("Authorization", "Bearer "+c.accessToken)
-
-	q := url.Values{}
-	q.Set("page_size", strconv.Itoa(pageSize))
-	if cursor != "" {
-		q.Set("cursor", cursor)
-	}
+	return response.MessageID, nil
+}
 
-	req.URL.RawQuery = q.Encode()
+func (c *client) sendGroupMessage(ctx context.Context, groupID string, message json.RawMessage) (messageID string, err error) {
+	ctx = withRecipient(ctx, GroupRecipient(groupID))
+	ctx, span := c.startSpan(ctx, "seatalkbot.SendGroupMessage", endpointGroupChat.path, GroupRecipient(groupID))
+	defer func() { endSpan(span, err) }()
 
-	resp, err := c.httpClient.Do(req)
+	response, err := do[sendGroupMessageReqBody, sendMessageRespBody](ctx, c, endpointGroupChat, sendGroupMessageReqBody{
+		GroupID: groupID,
+		Message: message,
+	})
 	if err != nil {
-		return nil, "", err
+		return "", err
 	}
 
-	defer resp.Body.Close()
+	return response.MessageID, nil
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, "", fmt.Errorf("status code not 200, got: %d", resp.StatusCode)
+// getEmployeeCodes resolves the inputs in batches of employeeLookupBatchSize. Inputs missing from the response
+// are reported as EmployeeNotFound.
+func (c *client) getEmployeeCodes(ctx context.Context, inputs []string, e endpoint, reqBody func(batch []string) any) ([]EmployeeLookup, error) {
+	lookups := make([]EmployeeLookup, 0, len(inputs))
+
+	for start := 0; start < len(inputs); start += employeeLookupBatchSize {
+		batch := inputs[start:min(start+employeeLookupBatchSize, len(inputs))]
+
+		response, err := do[any, getEmployeeCodesRespBody](ctx, c, e, reqBody(batch))
+		if err != nil {
+			return nil, err
+		}
+
+		found := make(map[string]EmployeeLookup, len(response.Employees))
+		for _, employee := range response.Employees {
+			input := employee.Email
+			if input == "" {
+				input = employee.Mobile
+			}
+
+			found[strings.ToLower(input)] = EmployeeLookup{
+				EmployeeCode: employee.EmployeeCode,
+				Status:       EmployeeStatus(employee.EmployeeStatus),
+			}
+		}
+
+		for _, input := range batch {
+			lookup, ok := found[strings.ToLower(input)]
+			if !ok || lookup.Status == 0 {
+				lookup.Status = EmployeeNotFound
+			}
+
+			lookup.Input = input
+			lookups = append(lookups, lookup)
+		}
 	}
 
-	respBody, err := io.ReadAll(resp.Body)
+	return lookups, nil
+}
+
+// fetchAccessToken gets a new access token by using the credentials.
+func (c *client) fetchAccessToken(ctx context.Context) (Token, error) {
+	response, err := do[accessTokenReqBody, accessTokenRespBody](ctx, c, endpointAccessToken, accessTokenReqBody{
+		AppID:     c.appID,
+		AppSecret: c.appSecret,
+	})
 	if err != nil {
-		return nil, "", err
+		return Token{}, err
 	}
 
-	response := getGroupIDsRespBody{
-		Code: -1, // To know if the code is not found in the response body.
+	if response.AppAccessToken == "" {
+		return Token{}, errors.New("access token not exist in response body")
 	}
-	err = json.Unmarshal(respBody, &response)
+
+	expiry := time.Now().Add(defaultTokenLifetime)
+	if response.Expire != 0 {
+		expiry = time.Unix(response.Expire, 0)
+	}
+
+	return Token{AccessToken: response.AppAccessToken, Expiry: expiry}, nil
+}
+
+func (c *client) getGroupIDs(ctx context.Context, cursor string) (groupIDs []string, nextCursor string, err error) {
+	ctx, span := c.startSpan(ctx, "seatalkbot.GetGroupIDs.page", endpointJoinedGroups.path, Recipient{})
+	defer func() { endSpan(span, err) }()
+
+	response, err := do[url.Values, getGroupIDsRespBody](ctx, c, endpointJoinedGroups, paginationQuery(cursor))
 	if err != nil {
 		return nil, "", err
 	}
 
-	if response.Code != 0 {
-		return nil, "", fmt.Errorf("error code is not 0, got: %d", response.Code)
+	return response.JoinedGroupChats.GroupIDs, response.NextCursor, nil
+}
+
+func (c *client) listGroupMembers(ctx context.Context, groupID, cursor string) (members []Employee, nextCursor string, err error) {
+	q := paginationQuery(cursor)
+	q.Set("group_id", groupID)
+
+	response, err := do[url.Values, listGroupMembersRespBody](ctx, c, endpointGroupMembers, q)
+	if err != nil {
+		return nil, "", err
 	}
 
-	return response.JoinedGroupChats.GroupIDs, response.NextCursor, nil
+	return response.Members, response.NextCursor, nil
 }
 
+// runAccessTokenScheduler refreshes the access token shortly before it expires. When the refresh fails,
+// it's retried every tokenRetryInterval until it succeeds or ctx is done. The refresh is rescheduled every time
+// a new access token is stored, e.g. by an API call finding the access token expired.
 func (c *client) runAccessTokenScheduler(ctx context.Context) {
 	go func() {
-		ticker := time.NewTicker(7000 * time.Second)
+		timer := time.NewTimer(c.tokens.refreshIn())
+		defer timer.Stop()
+
 		for {
 			select {
 			case <-ctx.Done():
 				return
-			case <-ticker.C:
-				_ = helper.RunWithRetry(
-					func() error {
-						return c.UpdateAccessToken(ctx)
-					},
-					-1,
-					10*time.Second,
-				)
+			case <-c.tokens.refreshed:
+				if !timer.Stop() {
+					select {
+					case <-timer.C:
+					default:
+					}
+				}
+				timer.Reset(max(c.tokens.refreshIn(), tokenRetryInterval))
+			case <-timer.C:
+				next := tokenRetryInterval
+
+				err := c.tokens.refresh(ctx)
+				switch {
+				case ctx.Err() != nil:
+					return
+				case err != nil:
+					c.logger.ErrorContext(ctx, "can't refresh access token in the background", slog.Any("error", err), slog.Duration("retry_in", next))
+				default:
+					next = max(c.tokens.refreshIn(), tokenRetryInterval)
+				}
+
+				timer.Reset(next)
 			}
 		}
 	}()
@@ -2,12 +2,20 @@ package seatalkbot
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
 )
 
 func Test_client_UpdateAccessToken(t *testing.T) {
@@ -51,8 +59,8 @@ func Test_client_UpdateAccessToken(t *testing.T) {
 			c, err := NewClient(Config{
 				HTTPClient: &http.Client{},
 				Host:       server.URL,
-				AppID:      "",
-				AppSecret:  "",
+				AppID:      "app-id",
+				AppSecret:  "app-secret",
 			})
 
 			tt.checkError(t, err)
@@ -64,6 +72,137 @@ func Test_client_UpdateAccessToken(t *testing.T) {
 	}
 }
 
+func Test_NewClient(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name       string
+		config     Config
+		opts       []Option
+		checkError require.ErrorAssertionFunc
+	}{
+		{
+			name:       "it should return error when the credentials are empty",
+			config:     Config{Host: "https://openapi.seatalk.io"},
+			checkError: require.Error,
+		},
+		{
+			name:       "it should return error when the host is not an http url",
+			config:     Config{Host: "openapi.seatalk.io", AppID: "app-id", AppSecret: "app-secret"},
+			checkError: require.Error,
+		},
+		{
+			name:       "it should return error when the host set by option is not an http url",
+			config:     Config{AppID: "app-id", AppSecret: "app-secret"},
+			opts:       []Option{WithHost("ftp://openapi.seatalk.io"), WithLazyInit()},
+			checkError: require.Error,
+		},
+		{
+			name:       "it should accept empty credentials when the token source is set",
+			config:     Config{TokenSource: StaticTokenSource("abc")},
+			checkError: require.NoError,
+		},
+		{
+			name:       "it should not get the access token when the init is lazy",
+			config:     Config{AppID: "app-id", AppSecret: "app-secret"},
+			opts:       []Option{WithHost("http://127.0.0.1:1"), WithLazyInit(), WithoutAutoRefresh()},
+			checkError: require.NoError,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			c, err := NewClient(tt.config, tt.opts...)
+
+			tt.checkError(t, err)
+
+			if err == nil {
+				assert.NoError(t, c.Close())
+			}
+		})
+	}
+}
+
+func Test_NewClientWithContext(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := NewClientWithContext(ctx, Config{AppID: "app-id", AppSecret: "app-secret"}, WithHost(server.URL))
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), time.Second, "it should stop when ctx is done")
+}
+
+func Test_NewClient_lazyInit(t *testing.T) {
+	t.Parallel()
+	var tokenRequests atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/auth/app_access_token":
+			n := tokenRequests.Add(1)
+			w.WriteHeader(http.StatusOK)
+			// the first access token is already expired
+			_, _ = fmt.Fprintf(w, `{"app_access_token":"abc%d","expire":%d}`, n, time.Now().Unix()-1+int64(n-1)*7200)
+
+		default:
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write([]byte(`{"code":0}`))
+		}
+	}))
+	defer server.Close()
+
+	c, err := NewClient(
+		Config{AppID: "app-id", AppSecret: "app-secret"},
+		WithHTTPClient(&http.Client{}),
+		WithHost(server.URL),
+		WithLazyInit(),
+		WithoutAutoRefresh(),
+	)
+	require.NoError(t, err)
+	defer c.Close()
+
+	assert.Equal(t, int32(0), tokenRequests.Load())
+	assert.Empty(t, c.AccessToken())
+
+	require.NoError(t, c.SendPrivateMessage(context.Background(), "123", TextMessage("abc", "")))
+	assert.Equal(t, int32(1), tokenRequests.Load())
+
+	require.NoError(t, c.SendPrivateMessage(context.Background(), "123", TextMessage("abc", "")))
+	assert.Equal(t, int32(2), tokenRequests.Load(), "it should refresh the expired access token")
+	assert.Equal(t, "abc2", c.AccessToken())
+
+	require.NoError(t, c.SendPrivateMessage(context.Background(), "123", TextMessage("abc", "")))
+	assert.Equal(t, int32(2), tokenRequests.Load())
+}
+
+func Test_NewClient_lazyInit_autoRefresh(t *testing.T) {
+	t.Parallel()
+	var calls atomic.Int32
+
+	c, err := NewClient(Config{
+		TokenSource: TokenSourceFunc(func(ctx context.Context) (Token, error) {
+			calls.Add(1)
+			return Token{AccessToken: "abc", Expiry: time.Now().Add(time.Hour)}, nil
+		}),
+	}, WithLazyInit())
+	require.NoError(t, err)
+	defer c.Close()
+
+	require.Eventually(t, func() bool {
+		return c.AccessToken() == "abc"
+	}, time.Second, time.Millisecond, "it should get the access token in the background without any api call")
+	assert.True(t, c.Health().Healthy())
+	assert.Equal(t, int32(1), calls.Load())
+}
+
 func Test_client_SendPrivateMessage(t *testing.T) {
 	t.Parallel()
 	tests := []struct {
@@ -141,8 +280,8 @@ func Test_client_SendPrivateMessage(t *testing.T) {
 			c, err := NewClient(Config{
 				HTTPClient: &http.Client{},
 				Host:       server.URL,
-				AppID:      "",
-				AppSecret:  "",
+				AppID:      "app-id",
+				AppSecret:  "app-secret",
 			})
 
 			require.NoError(t, err)
@@ -205,6 +344,25 @@ func Test_client_GetGroupIDs(t *testing.T) {
 			},
 			checkError: require.Error,
 		},
+		{
+			name: "it should return groupIDs from every page when there are multiple pages",
+			handlerFunc: func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/auth/app_access_token":
+					w.WriteHeader(http.StatusOK)
+					_, _ = w.Write([]byte(`{"app_access_token":"abc"}`))
+
+				default:
+					w.WriteHeader(http.StatusOK)
+					if r.URL.Query().Get("cursor") == "" {
+						_, _ = w.Write([]byte(`{"code":0,"next_cursor":"c1","joined_group_chats":{"group_id":[]}}`))
+						return
+					}
+					_, _ = w.Write([]byte(`{"code":0,"next_cursor":"","joined_group_chats":{"group_id":["abc"]}}`))
+				}
+			},
+			checkError: require.NoError,
+		},
 		{
 			name: "it should return groupIDs and nil error when response body code is 0",
 			handlerFunc: func(w http.ResponseWriter, r *http.Request) {
@@ -231,8 +389,8 @@ func Test_client_GetGroupIDs(t *testing.T) {
 			c, err := NewClient(Config{
 				HTTPClient: &http.Client{},
 				Host:       server.URL,
-				AppID:      "",
-				AppSecret:  "",
+				AppID:      "app-id",
+				AppSecret:  "app-secret",
 			})
 
 			require.NoError(t, err)
@@ -324,8 +482,8 @@ func Test_client_SendGroupMessage(t *testing.T) {
 			c, err := NewClient(Config{
 				HTTPClient: &http.Client{},
 				Host:       server.URL,
-				AppID:      "",
-				AppSecret:  "",
+				AppID:      "app-id",
+				AppSecret:  "app-secret",
 			})
 
 			require.NoError(t, err)
@@ -339,3 +497,520 @@ func Test_client_SendGroupMessage(t *testing.T) {
 		})
 	}
 }
+
+func Test_client_UpdateInteractiveMessage(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name        string
+		handlerFunc func(http.ResponseWriter, *http.Request)
+		checkError  require.ErrorAssertionFunc
+	}{
+		{
+			name: "it should return error when status code is not 200",
+			handlerFunc: func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/auth/app_access_token":
+					w.WriteHeader(http.StatusOK)
+					_, _ = w.Write([]byte(`{"app_access_token":"abc"}`))
+
+				default:
+					w.WriteHeader(http.StatusInternalServerError)
+				}
+			},
+			checkError: require.Error,
+		},
+		{
+			name: "it should return error when response body code is not 0",
+			handlerFunc: func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/auth/app_access_token":
+					w.WriteHeader(http.StatusOK)
+					_, _ = w.Write([]byte(`{"app_access_token":"abc"}`))
+
+				default:
+					w.WriteHeader(http.StatusOK)
+					_, _ = w.Write([]byte(`{"code":100}`))
+				}
+			},
+			checkError: require.Error,
+		},
+		{
+			name: "it should return nil when response body code is 0",
+			handlerFunc: func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/auth/app_access_token":
+					w.WriteHeader(http.StatusOK)
+					_, _ = w.Write([]byte(`{"app_access_token":"abc"}`))
+
+				case "/messaging/v2/update":
+					w.WriteHeader(http.StatusOK)
+					_, _ = w.Write([]byte(`{"code":0}`))
+
+				default:
+					w.WriteHeader(http.StatusNotFound)
+				}
+			},
+			checkError: require.NoError,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			server := httptest.NewServer(http.HandlerFunc(tt.handlerFunc))
+			defer server.Close()
+
+			c, err := NewClient(Config{
+				HTTPClient: &http.Client{},
+				Host:       server.URL,
+				AppID:      "app-id",
+				AppSecret:  "app-secret",
+			})
+
+			require.NoError(t, err)
+
+			message, err := NewInteractiveMessage().Title("Approved by abc").Build()
+			require.NoError(t, err)
+
+			err = c.UpdateInteractiveMessage(context.Background(), "123", message)
+
+			tt.checkError(t, err)
+		})
+	}
+}
+
+func Test_client_ReplyInThread(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name        string
+		threadID    string
+		handlerFunc func(http.ResponseWriter, *http.Request)
+		checkError  require.ErrorAssertionFunc
+	}{
+		{
+			name:     "it should return error when thread id is empty",
+			threadID: "",
+			handlerFunc: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+				_, _ = w.Write([]byte(`{"app_access_token":"abc"}`))
+			},
+			checkError: require.Error,
+		},
+		{
+			name:     "it should return error when response body code is not 0",
+			threadID: "t1",
+			handlerFunc: func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/auth/app_access_token":
+					w.WriteHeader(http.StatusOK)
+					_, _ = w.Write([]byte(`{"app_access_token":"abc"}`))
+
+				default:
+					w.WriteHeader(http.StatusOK)
+					_, _ = w.Write([]byte(`{"code":100}`))
+				}
+			},
+			checkError: require.Error,
+		},
+		{
+			name:     "it should send the thread id in the message and return messageID",
+			threadID: "t1",
+			handlerFunc: func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/auth/app_access_token":
+					w.WriteHeader(http.StatusOK)
+					_, _ = w.Write([]byte(`{"app_access_token":"abc"}`))
+
+				default:
+					body, _ := io.ReadAll(r.Body)
+					if gjson.GetBytes(body, "message.thread_id").String() != "t1" {
+						w.WriteHeader(http.StatusBadRequest)
+						return
+					}
+
+					w.WriteHeader(http.StatusOK)
+					_, _ = w.Write([]byte(`{"code":0,"message_id":"abc"}`))
+				}
+			},
+			checkError: require.NoError,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			server := httptest.NewServer(http.HandlerFunc(tt.handlerFunc))
+			defer server.Close()
+
+			c, err := NewClient(Config{
+				HTTPClient: &http.Client{},
+				Host:       server.URL,
+				AppID:      "app-id",
+				AppSecret:  "app-secret",
+			})
+
+			require.NoError(t, err)
+
+			messageID, err := c.ReplyInThread(context.Background(), "123", tt.threadID, TextMessage("abc", ""))
+
+			tt.checkError(t, err)
+			if err == nil {
+				assert.Equal(t, "abc", messageID)
+			}
+		})
+	}
+}
+
+func Test_client_GetEmployeeCodesByEmail(t *testing.T) {
+	t.Parallel()
+
+	var requests atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/auth/app_access_token":
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write([]byte(`{"app_access_token":"abc"}`))
+
+		case "/contacts/v2/get_employee_code_with_email":
+			requests.Add(1)
+			body, _ := io.ReadAll(r.Body)
+
+			var employees []map[string]any
+			for _, email := range gjson.GetBytes(body, "emails").Array() {
+				switch email.String() {
+				case "missing@example.com":
+				case "inactive@example.com":
+					employees = append(employees, map[string]any{"email": email.String(), "employee_code": "2", "employee_status": 3})
+				default:
+					employees = append(employees, map[string]any{"email": email.String(), "employee_code": "1", "employee_status": 2})
+				}
+			}
+
+			w.WriteHeader(http.StatusOK)
+			_ = json.NewEncoder(w).Encode(map[string]any{"code": 0, "employees": employees})
+
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	c, err := NewClient(Config{
+		HTTPClient: &http.Client{},
+		Host:       server.URL,
+		AppID:      "app-id",
+		AppSecret:  "app-secret",
+	})
+	require.NoError(t, err)
+
+	emails := make([]string, employeeLookupBatchSize)
+	for i := range emails {
+		emails[i] = "active@example.com"
+	}
+	emails = append(emails, "missing@example.com", "inactive@example.com")
+
+	lookups, err := c.GetEmployeeCodesByEmail(context.Background(), emails)
+
+	require.NoError(t, err)
+	require.Len(t, lookups, len(emails))
+	assert.Equal(t, int32(2), requests.Load())
+	assert.Equal(t, EmployeeLookup{Input: "active@example.com", EmployeeCode: "1", Status: EmployeeActive}, lookups[0])
+	assert.Equal(t, EmployeeLookup{Input: "missing@example.com", Status: EmployeeNotFound}, lookups[len(lookups)-2])
+	assert.Equal(t, EmployeeLookup{Input: "inactive@example.com", EmployeeCode: "2", Status: EmployeeInactive}, lookups[len(lookups)-1])
+}
+
+func Test_client_GetGroupInfo(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name        string
+		handlerFunc func(http.ResponseWriter, *http.Request)
+		checkError  require.ErrorAssertionFunc
+	}{
+		{
+			name: "it should return error when response body code is not 0",
+			handlerFunc: func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/auth/app_access_token":
+					w.WriteHeader(http.StatusOK)
+					_, _ = w.Write([]byte(`{"app_access_token":"abc"}`))
+
+				default:
+					w.WriteHeader(http.StatusOK)
+					_, _ = w.Write([]byte(`{"code":100}`))
+				}
+			},
+			checkError: require.Error,
+		},
+		{
+			name: "it should return group info and nil error when response body code is 0",
+			handlerFunc: func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/auth/app_access_token":
+					w.WriteHeader(http.StatusOK)
+					_, _ = w.Write([]byte(`{"app_access_token":"abc"}`))
+
+				default:
+					if r.URL.Query().Get("group_id") != "g1" {
+						w.WriteHeader(http.StatusBadRequest)
+						return
+					}
+
+					w.WriteHeader(http.StatusOK)
+					_, _ = w.Write([]byte(`{"code":0,"group":{"group_name":"abc","group_user_total":3,"group_settings":{"can_view_member_list":true}}}`))
+				}
+			},
+			checkError: require.NoError,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			server := httptest.NewServer(http.HandlerFunc(tt.handlerFunc))
+			defer server.Close()
+
+			c, err := NewClient(Config{
+				HTTPClient: &http.Client{},
+				Host:       server.URL,
+				AppID:      "app-id",
+				AppSecret:  "app-secret",
+			})
+
+			require.NoError(t, err)
+
+			info, err := c.GetGroupInfo(context.Background(), "g1")
+
+			tt.checkError(t, err)
+			if err == nil {
+				assert.Equal(t, GroupInfo{
+					GroupID:     "g1",
+					GroupName:   "abc",
+					MemberCount: 3,
+					Settings:    GroupSettings{CanViewMemberList: true},
+				}, info)
+			}
+		})
+	}
+}
+
+func Test_client_ListGroupMembers(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/auth/app_access_token":
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write([]byte(`{"app_access_token":"abc"}`))
+
+		case "/messaging/v2/group_chat/members":
+			w.WriteHeader(http.StatusOK)
+			if r.URL.Query().Get("cursor") == "" {
+				_, _ = w.Write([]byte(`{"code":0,"next_cursor":"c1","members":[{"employee_code":"1"}]}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"code":0,"next_cursor":"","members":[{"employee_code":"2"}]}`))
+
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	c, err := NewClient(Config{
+		HTTPClient: &http.Client{},
+		Host:       server.URL,
+		AppID:      "app-id",
+		AppSecret:  "app-secret",
+	})
+	require.NoError(t, err)
+
+	members, err := c.ListGroupMembers(context.Background(), "g1")
+
+	require.NoError(t, err)
+	assert.Equal(t, []Employee{{EmployeeCode: "1"}, {EmployeeCode: "2"}}, members)
+}
+
+func Test_client_IterateGroupIDs(t *testing.T) {
+	t.Parallel()
+	// pages maps the cursor to the response body of the page.
+	pages := map[string]string{
+		"":   `{"code":0,"next_cursor":"c1","joined_group_chats":{"group_id":["g1","g2"]}}`,
+		"c1": `{"code":0,"next_cursor":"c2","joined_group_chats":{"group_id":["g3"]}}`,
+		"c2": `{"code":0,"next_cursor":"","joined_group_chats":{"group_id":["g4"]}}`,
+	}
+	tests := []struct {
+		name         string
+		ctx          func() context.Context
+		stopAfter    int
+		checkError   require.ErrorAssertionFunc
+		wantGroupIDs []string
+		wantPages    int32
+	}{
+		{
+			name:         "it should fetch every page until next cursor is empty",
+			ctx:          context.Background,
+			checkError:   require.NoError,
+			wantGroupIDs: []string{"g1", "g2", "g3", "g4"},
+			wantPages:    3,
+		},
+		{
+			name:         "it should stop fetching when fn returns false",
+			ctx:          context.Background,
+			stopAfter:    2,
+			checkError:   require.NoError,
+			wantGroupIDs: []string{"g1", "g2"},
+			wantPages:    1,
+		},
+		{
+			name: "it should return error when context is canceled",
+			ctx: func() context.Context {
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+				return ctx
+			},
+			checkError: require.Error,
+			wantPages:  0,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var fetchedPages atomic.Int32
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/auth/app_access_token":
+					w.WriteHeader(http.StatusOK)
+					_, _ = w.Write([]byte(`{"app_access_token":"abc"}`))
+
+				case "/messaging/v2/group_chat/joined":
+					fetchedPages.Add(1)
+					w.WriteHeader(http.StatusOK)
+					_, _ = w.Write([]byte(pages[r.URL.Query().Get("cursor")]))
+
+				default:
+					w.WriteHeader(http.StatusNotFound)
+				}
+			}))
+			defer server.Close()
+
+			c, err := NewClient(Config{
+				HTTPClient: &http.Client{},
+				Host:       server.URL,
+				AppID:      "app-id",
+				AppSecret:  "app-secret",
+			})
+			require.NoError(t, err)
+
+			var groupIDs []string
+			err = c.IterateGroupIDs(tt.ctx(), func(groupID string) bool {
+				groupIDs = append(groupIDs, groupID)
+				return tt.stopAfter == 0 || len(groupIDs) < tt.stopAfter
+			})
+
+			tt.checkError(t, err)
+			assert.Equal(t, tt.wantGroupIDs, groupIDs)
+			assert.Equal(t, tt.wantPages, fetchedPages.Load())
+		})
+	}
+}
+
+func Test_client_refreshRejectedAccessToken(t *testing.T) {
+	t.Parallel()
+
+	var issued atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/auth/app_access_token":
+			n := issued.Add(1)
+			time.Sleep(10 * time.Millisecond) // keep the refresh in flight while other sends are rejected.
+
+			w.WriteHeader(http.StatusOK)
+			_, _ = fmt.Fprintf(w, `{"app_access_token":"t%d","expire":%d}`, n, time.Now().Add(time.Hour).Unix())
+
+		case "/messaging/v2/single_chat":
+			w.WriteHeader(http.StatusOK)
+			if r.Header.Get("Authorization") == "Bearer t1" {
+				_, _ = w.Write([]byte(`{"code":100}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"code":0}`))
+
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	c, err := NewClient(Config{
+		HTTPClient: &http.Client{},
+		Host:       server.URL,
+		AppID:      "app-id",
+		AppSecret:  "app-secret",
+	}, WithoutRateLimiter())
+	require.NoError(t, err)
+	defer c.Close()
+
+	require.Equal(t, "t1", c.AccessToken())
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 20)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- c.SendPrivateMessage(context.Background(), "123", TextMessage("abc", ""))
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, "t2", c.AccessToken())
+	assert.Equal(t, int32(2), issued.Load(), "concurrent refreshes should be coalesced into one request")
+}
+
+func Test_tokenManager_refresh_callerCancelled(t *testing.T) {
+	t.Parallel()
+
+	release := make(chan struct{})
+	source := TokenSourceFunc(func(ctx context.Context) (Token, error) {
+		select {
+		case <-release:
+			return Token{AccessToken: "abc", Expiry: time.Now().Add(time.Hour)}, nil
+		case <-ctx.Done():
+			return Token{}, ctx.Err()
+		}
+	})
+
+	var reported atomic.Int32
+	m := newTokenManager(source, slog.New(discardHandler{}), noopMetrics{}, noopTracer{}, func(error, Health) { reported.Add(1) })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	first := make(chan error, 1)
+	go func() { first <- m.refresh(ctx) }()
+
+	require.Eventually(t, func() bool {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		return m.refreshing != nil
+	}, time.Second, time.Millisecond)
+
+	second := make(chan error, 1)
+	go func() { second <- m.refresh(context.Background()) }()
+
+	cancel()
+	assert.ErrorIs(t, <-first, context.Canceled)
+
+	close(release)
+	require.NoError(t, <-second)
+
+	assert.Equal(t, "abc", m.token())
+	assert.Zero(t, m.health().ConsecutiveFailures)
+	assert.NoError(t, m.health().LastError)
+	assert.Zero(t, reported.Load())
+}
@@ -0,0 +1,35 @@
+package seatalkbot
+
+// EmployeeStatus is the status of an employee returned by the employee lookup.
+type EmployeeStatus int
+
+const (
+	// EmployeeNotFound means there is no employee with the email or mobile.
+	EmployeeNotFound EmployeeStatus = 1
+	// EmployeeActive means the employee is active and can receive messages.
+	EmployeeActive EmployeeStatus = 2
+	// EmployeeInactive means the employee is deactivated.
+	EmployeeInactive EmployeeStatus = 3
+)
+
+func (s EmployeeStatus) String() string {
+	switch s {
+	case EmployeeNotFound:
+		return "not found"
+	case EmployeeActive:
+		return "active"
+	case EmployeeInactive:
+		return "inactive"
+	default:
+		return "unknown"
+	}
+}
+
+// EmployeeLookup is the result of resolving an email or a mobile number to an employee code.
+type EmployeeLookup struct {
+	// Input is the email or mobile number that was looked up.
+	Input string
+	// EmployeeCode is empty when the Status is EmployeeNotFound.
+	EmployeeCode string
+	Status       EmployeeStatus
+}
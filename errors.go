@@ -0,0 +1,127 @@
+package seatalkbot
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/tidwall/gjson"
+)
+
+// Codes returned by seatalk in the response body when the API call fails.
+const (
+	// CodeAccessTokenInvalid means the access token is expired or invalid.
+	CodeAccessTokenInvalid = 100
+	// CodeRateLimited means the API call is rejected by the rate limit.
+	CodeRateLimited = 101
+	// CodeInvalidRequest means the request body or query is invalid.
+	CodeInvalidRequest = 102
+	// CodePermissionDenied means the app doesn't have the permission to call the API.
+	CodePermissionDenied = 103
+	// CodeUserNotFound means there is no user with the employee code.
+	CodeUserNotFound = 3000
+	// CodeUserNotSubscriber means the user has not subscribed to the bot.
+	CodeUserNotSubscriber = 3001
+	// CodeGroupNotFound means there is no group chat with the group id.
+	CodeGroupNotFound = 7000
+	// CodeBotNotInGroup means the bot is not a member of the group chat.
+	CodeBotNotInGroup = 7001
+)
+
+// requestIDHeader is the response header containing the id of the request, useful when reporting an issue to seatalk.
+const requestIDHeader = "X-Request-Id"
+
+// APIError is returned when an API call fails with a status code other than 200 or a code other than 0.
+// Use errors.As to get it from the error returned by the Client.
+type APIError struct {
+	// StatusCode is the http status code of the response.
+	StatusCode int
+	// Code is the code in the response body. It's 0 when the response body doesn't contain a code,
+	// e.g. when StatusCode is not 200.
+	Code int
+	// Message is the message in the response body.
+	Message string
+	// Endpoint is the path of the API.
+	Endpoint string
+	// RequestID is the id of the request returned by seatalk, it might be empty.
+	RequestID string
+
+	// retryAfter is the duration from the Retry-After header.
+	retryAfter time.Duration
+}
+
+func (e *APIError) Error() string {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "seatalk api error, endpoint: %s, status_code: %d, code: %d", e.Endpoint, e.StatusCode, e.Code)
+	if e.Message != "" {
+		fmt.Fprintf(&sb, ", message: %s", e.Message)
+	}
+	if e.RequestID != "" {
+		fmt.Fprintf(&sb, ", request_id: %s", e.RequestID)
+	}
+
+	return sb.String()
+}
+
+// newAPIError returns the APIError of the response, or nil when the response is successful. codeRequired tells
+// whether a response body without the code is an error.
+func newAPIError(resp *http.Response, respBody []byte, codeRequired bool) *APIError {
+	apiErr := &APIError{
+		StatusCode: resp.StatusCode,
+		Endpoint:   resp.Request.URL.Path,
+		RequestID:  resp.Header.Get(requestIDHeader),
+		retryAfter: parseRetryAfter(resp.Header.Get("Retry-After")),
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		apiErr.Code = int(gjson.GetBytes(respBody, "code").Int())
+		apiErr.Message = gjson.GetBytes(respBody, "message").String()
+		return apiErr
+	}
+
+	code := gjson.GetBytes(respBody, "code")
+	if !code.Exists() && !codeRequired {
+		return nil
+	}
+	if !code.Exists() {
+		apiErr.Message = fmt.Sprintf("code in response body is not exist, resp_body: %s", respBody)
+		return apiErr
+	}
+	if code.Int() == 0 {
+		return nil
+	}
+
+	apiErr.Code = int(code.Int())
+	apiErr.Message = gjson.GetBytes(respBody, "message").String()
+
+	return apiErr
+}
+
+// IsRateLimited reports whether the API call is rejected by the rate limit of seatalk.
+func IsRateLimited(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && (apiErr.Code == CodeRateLimited || apiErr.StatusCode == http.StatusTooManyRequests)
+}
+
+// IsTokenExpired reports whether the API call is rejected because the access token is expired or invalid.
+func IsTokenExpired(err error) bool {
+	return hasCode(err, CodeAccessTokenInvalid)
+}
+
+// IsUserNotFound reports whether the API call is rejected because the user doesn't exist.
+func IsUserNotFound(err error) bool {
+	return hasCode(err, CodeUserNotFound)
+}
+
+// IsBotNotInGroup reports whether the API call is rejected because the bot is not a member of the group chat.
+func IsBotNotInGroup(err error) bool {
+	return hasCode(err, CodeBotNotInGroup)
+}
+
+func hasCode(err error, code int) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.Code == code
+}
@@ -0,0 +1,108 @@
+package seatalkbot
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_APIError(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name           string
+		statusCode     int
+		body           string
+		wantErr        *APIError
+		wantRateLimit  bool
+		wantNotInGroup bool
+	}{
+		{
+			name:       "it should return APIError with status code when status code is not 200",
+			statusCode: http.StatusBadGateway,
+			body:       ``,
+			wantErr: &APIError{
+				StatusCode: http.StatusBadGateway,
+				Endpoint:   "/messaging/v2/group_chat",
+				RequestID:  "req-1",
+			},
+		},
+		{
+			name:       "it should return APIError that is rate limited when status code is 429",
+			statusCode: http.StatusTooManyRequests,
+			body:       ``,
+			wantErr: &APIError{
+				StatusCode: http.StatusTooManyRequests,
+				Endpoint:   "/messaging/v2/group_chat",
+				RequestID:  "req-1",
+			},
+			wantRateLimit: true,
+		},
+		{
+			name:       "it should return APIError that is rate limited when code is 101",
+			statusCode: http.StatusOK,
+			body:       `{"code":101,"message":"rate limited"}`,
+			wantErr: &APIError{
+				StatusCode: http.StatusOK,
+				Code:       CodeRateLimited,
+				Message:    "rate limited",
+				Endpoint:   "/messaging/v2/group_chat",
+				RequestID:  "req-1",
+			},
+			wantRateLimit: true,
+		},
+		{
+			name:       "it should return APIError that is bot not in group when code is 7001",
+			statusCode: http.StatusOK,
+			body:       `{"code":7001,"message":"bot is not in the group"}`,
+			wantErr: &APIError{
+				StatusCode: http.StatusOK,
+				Code:       CodeBotNotInGroup,
+				Message:    "bot is not in the group",
+				Endpoint:   "/messaging/v2/group_chat",
+				RequestID:  "req-1",
+			},
+			wantNotInGroup: true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/auth/app_access_token":
+					w.WriteHeader(http.StatusOK)
+					_, _ = w.Write([]byte(`{"app_access_token":"abc"}`))
+
+				default:
+					w.Header().Set("X-Request-Id", "req-1")
+					w.WriteHeader(tt.statusCode)
+					_, _ = w.Write([]byte(tt.body))
+				}
+			}))
+			defer server.Close()
+
+			c, err := NewClient(Config{
+				HTTPClient: &http.Client{},
+				Host:       server.URL,
+				AppID:      "app-id",
+				AppSecret:  "app-secret",
+			})
+			require.NoError(t, err)
+
+			_, err = c.SendGroupMessage(context.Background(), "123", TextMessage("abc", ""))
+
+			var apiErr *APIError
+			require.True(t, errors.As(err, &apiErr))
+			assert.Equal(t, tt.wantErr, apiErr)
+			assert.Equal(t, tt.wantRateLimit, IsRateLimited(err))
+			assert.Equal(t, tt.wantNotInGroup, IsBotNotInGroup(err))
+			assert.False(t, IsTokenExpired(err))
+		})
+	}
+}
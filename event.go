@@ -0,0 +1,127 @@
+package seatalkbot
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/anandawira/seatalkbot/helper"
+)
+
+const (
+	// signatureHeader is the header containing the signature of the request body.
+	signatureHeader = "Signature"
+	// maxEventBodySize is the maximum size of the event request body that will be read.
+	maxEventBodySize = 1 << 20
+)
+
+// Event is the envelope of every event callback sent by seatalk.
+type Event struct {
+	EventID   string `json:"event_id"`
+	EventType string `json:"event_type"`
+	Timestamp int64  `json:"timestamp"`
+	AppID     string `json:"app_id"`
+	// Event is the payload of the event, its content depends on the EventType.
+	Event json.RawMessage `json:"event"`
+}
+
+// EventListener handles the verified events received by the EventHandler.
+type EventListener interface {
+	// HandleEvent is called for every verified event other than event_verification.
+	// Returning an error makes the EventHandler respond with status code 500.
+	HandleEvent(ctx context.Context, event Event) error
+}
+
+// EventListenerFunc is an adapter to allow the use of ordinary functions as EventListener.
+type EventListenerFunc func(ctx context.Context, event Event) error
+
+// HandleEvent implements EventListener
+func (f EventListenerFunc) HandleEvent(ctx context.Context, event Event) error {
+	return f(ctx, event)
+}
+
+type EventHandlerConfig struct {
+	// SigningSecret of the seatalk bot. It can be found in the app setting at the seatalk dashboard.
+	SigningSecret string
+	// Listener will be called for every verified event.
+	Listener EventListener
+}
+
+type eventHandler struct {
+	signingSecret string
+	listener      EventListener
+}
+
+// NewEventHandler returns an http.Handler that receives the event callbacks from seatalk. It verifies the signature
+// of every request, answers the event_verification challenge and passes the other events to the Listener.
+func NewEventHandler(config EventHandlerConfig) (http.Handler, error) {
+	if config.SigningSecret == "" {
+		return nil, errors.New("signing secret should not be empty")
+	}
+	if config.Listener == nil {
+		return nil, errors.New("listener should not be nil")
+	}
+
+	return &eventHandler{
+		signingSecret: config.SigningSecret,
+		listener:      config.Listener,
+	}, nil
+}
+
+// ServeHTTP implements http.Handler
+func (h *eventHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxEventBodySize))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if !h.validSignature(body, r.Header.Get(signatureHeader)) {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	event, err := helper.UnmarshalJSON[Event](body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if event.EventType == EventTypeEventVerification {
+		verification, err := helper.UnmarshalJSON[eventVerification](event.Event)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		_ = json.NewEncoder(w).Encode(verification)
+		return
+	}
+
+	if err := h.listener.HandleEvent(r.Context(), event); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// validSignature checks the signature, which is the hex encoded sha256 of the request body followed by the signing secret.
+func (h *eventHandler) validSignature(body []byte, signature string) bool {
+	hash := sha256.Sum256(append(body, h.signingSecret...))
+	expected := hex.EncodeToString(hash[:])
+
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(signature)) == 1
+}
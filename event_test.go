@@ -0,0 +1,116 @@
+package seatalkbot
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSigningSecret = "secret"
+
+func sign(body string) string {
+	hash := sha256.Sum256([]byte(body + testSigningSecret))
+	return hex.EncodeToString(hash[:])
+}
+
+func Test_eventHandler_ServeHTTP(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name           string
+		method         string
+		body           string
+		signature      string
+		listenerErr    error
+		wantStatusCode int
+		wantBody       string
+		wantEvent      *Event
+	}{
+		{
+			name:           "it should return 405 when method is not POST",
+			method:         http.MethodGet,
+			wantStatusCode: http.StatusMethodNotAllowed,
+		},
+		{
+			name:           "it should return 401 when signature is invalid",
+			method:         http.MethodPost,
+			body:           `{"event_type":"new_bot_subscriber"}`,
+			signature:      "invalid",
+			wantStatusCode: http.StatusUnauthorized,
+		},
+		{
+			name:           "it should return 400 when body is not a valid json",
+			method:         http.MethodPost,
+			body:           `not json`,
+			signature:      sign(`not json`),
+			wantStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:           "it should answer the challenge when event type is event_verification",
+			method:         http.MethodPost,
+			body:           `{"event_id":"1","event_type":"event_verification","event":{"seatalk_challenge":"abc"}}`,
+			signature:      sign(`{"event_id":"1","event_type":"event_verification","event":{"seatalk_challenge":"abc"}}`),
+			wantStatusCode: http.StatusOK,
+			wantBody:       `{"seatalk_challenge":"abc"}`,
+		},
+		{
+			name:           "it should return 500 when listener returns error",
+			method:         http.MethodPost,
+			body:           `{"event_id":"1","event_type":"new_bot_subscriber","event":{}}`,
+			signature:      sign(`{"event_id":"1","event_type":"new_bot_subscriber","event":{}}`),
+			listenerErr:    errors.New("some error"),
+			wantStatusCode: http.StatusInternalServerError,
+		},
+		{
+			name:           "it should pass the event to the listener and return 200",
+			method:         http.MethodPost,
+			body:           `{"event_id":"1","event_type":"new_bot_subscriber","timestamp":123,"app_id":"app","event":{"employee_code":"abc"}}`,
+			signature:      sign(`{"event_id":"1","event_type":"new_bot_subscriber","timestamp":123,"app_id":"app","event":{"employee_code":"abc"}}`),
+			wantStatusCode: http.StatusOK,
+			wantEvent: &Event{
+				EventID:   "1",
+				EventType: "new_bot_subscriber",
+				Timestamp: 123,
+				AppID:     "app",
+				Event:     []byte(`{"employee_code":"abc"}`),
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var got *Event
+
+			handler, err := NewEventHandler(EventHandlerConfig{
+				SigningSecret: testSigningSecret,
+				Listener: EventListenerFunc(func(ctx context.Context, event Event) error {
+					got = &event
+					return tt.listenerErr
+				}),
+			})
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(tt.method, "/callback", strings.NewReader(tt.body))
+			req.Header.Set("Signature", tt.signature)
+			rec := httptest.NewRecorder()
+
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.wantStatusCode, rec.Code)
+			if tt.wantBody != "" {
+				assert.JSONEq(t, tt.wantBody, rec.Body.String())
+			}
+			if tt.wantEvent != nil {
+				assert.Equal(t, tt.wantEvent, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package seatalkbot
+
+const (
+	// EventTypeEventVerification is sent by seatalk to verify the callback url. It's answered by the EventHandler.
+	EventTypeEventVerification = "event_verification"
+	// EventTypeNewBotSubscriber is sent when a user subscribes to the bot.
+	EventTypeNewBotSubscriber = "new_bot_subscriber"
+	// EventTypeMessageFromBotSubscriber is sent when a subscriber sends a private message to the bot.
+	EventTypeMessageFromBotSubscriber = "message_from_bot_subscriber"
+	// EventTypeNewMentionedMessageFromGroupChat is sent when the bot is mentioned in a group chat.
+	EventTypeNewMentionedMessageFromGroupChat = "new_mentioned_message_received_from_group_chat"
+	// EventTypeBotAddedToGroupChat is sent when the bot is added to a group chat.
+	EventTypeBotAddedToGroupChat = "bot_added_to_group_chat"
+	// EventTypeBotRemovedFromGroupChat is sent when the bot is removed from a group chat.
+	EventTypeBotRemovedFromGroupChat = "bot_removed_from_group_chat"
+	// EventTypeInteractiveMessageClick is sent when a user clicks a callback button of an interactive message.
+	EventTypeInteractiveMessageClick = "interactive_message_click"
+)
+
+type eventVerification struct {
+	SeatalkChallenge string `json:"seatalk_challenge"`
+}
+
+// Employee identifies a seatalk user, e.g. the one that triggered an event or a member returned by
+// ListGroupMembers. The fields not returned by the API are left empty.
+type Employee struct {
+	SeatalkID    string `json:"seatalk_id"`
+	EmployeeCode string `json:"employee_code"`
+	Email        string `json:"email"`
+}
+
+// Group is the group chat in which an event happened.
+type Group struct {
+	GroupID   string `json:"group_id"`
+	GroupName string `json:"group_name"`
+}
+
+// NewBotSubscriberEvent is the payload of the new_bot_subscriber event.
+type NewBotSubscriberEvent struct {
+	Employee
+}
+
+// PrivateMessageEvent is the payload of the message_from_bot_subscriber event.
+type PrivateMessageEvent struct {
+	Employee
+	Message PrivateMessage `json:"message"`
+}
+
+// PrivateMessage is the message sent by a subscriber to the bot.
+type PrivateMessage struct {
+	MessageID       string `json:"message_id"`
+	QuotedMessageID string `json:"quoted_message_id"`
+	Tag             string `json:"tag"`
+	Text            struct {
+		Content string `json:"content"`
+	} `json:"text"`
+	Image struct {
+		// Content is the url to download the image.
+		Content string `json:"content"`
+	} `json:"image"`
+	File struct {
+		// Content is the url to download the file.
+		Content  string `json:"content"`
+		Filename string `json:"filename"`
+	} `json:"file"`
+}
+
+// GroupMentionEvent is the payload of the new_mentioned_message_received_from_group_chat event.
+type GroupMentionEvent struct {
+	GroupID string       `json:"group_id"`
+	Message GroupMessage `json:"message"`
+}
+
+// GroupMessage is the message in which the bot is mentioned.
+type GroupMessage struct {
+	MessageID       string `json:"message_id"`
+	QuotedMessageID string `json:"quoted_message_id"`
+	// ThreadID is the id of the thread the message is sent into, it's empty when sent to the main timeline.
+	// Use it with Client.ReplyInThread to reply in the same thread.
+	ThreadID string `json:"thread_id"`
+	Sender   struct {
+		Employee
+		SenderType int `json:"sender_type"`
+	} `json:"sender"`
+	MessageSentTime int64  `json:"message_sent_time"`
+	Tag             string `json:"tag"`
+	Text            struct {
+		PlainText     string `json:"plain_text"`
+		MentionedList []struct {
+			Employee
+			Username string `json:"username"`
+		} `json:"mentioned_list"`
+	} `json:"text"`
+}
+
+// BotAddedToGroupEvent is the payload of the bot_added_to_group_chat event.
+type BotAddedToGroupEvent struct {
+	Group   Group    `json:"group"`
+	Inviter Employee `json:"inviter"`
+}
+
+// BotRemovedFromGroupEvent is the payload of the bot_removed_from_group_chat event.
+type BotRemovedFromGroupEvent struct {
+	Group   Group    `json:"group"`
+	Remover Employee `json:"remover"`
+}
+
+// InteractiveClickEvent is the payload of the interactive_message_click event.
+type InteractiveClickEvent struct {
+	Employee
+	MessageID string `json:"message_id"`
+	// Value is the value of the clicked callback button.
+	Value string `json:"value"`
+	// GroupID is empty when the interactive message is sent in a private chat.
+	GroupID string `json:"group_id"`
+	// ThreadID is the id of the thread the interactive message is sent into, it's empty when sent to the main timeline.
+	ThreadID string `json:"thread_id"`
+}
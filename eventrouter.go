@@ -0,0 +1,84 @@
+package seatalkbot
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/anandawira/seatalkbot/helper"
+)
+
+// EventRouter is an EventListener that decodes the events into their typed payload and dispatches them to the
+// registered callbacks. Events without a registered callback, including unknown event types, are passed to the
+// fallback listener if set, and ignored otherwise.
+// The callbacks must be registered before the router starts receiving events.
+type EventRouter struct {
+	routes   map[string]EventListenerFunc
+	fallback EventListener
+}
+
+// NewEventRouter returns an EventRouter without any registered callback.
+func NewEventRouter() *EventRouter {
+	return &EventRouter{
+		routes: make(map[string]EventListenerFunc),
+	}
+}
+
+// OnNewBotSubscriber registers the callback for the new_bot_subscriber event.
+func (r *EventRouter) OnNewBotSubscriber(fn func(ctx context.Context, event NewBotSubscriberEvent) error) {
+	r.routes[EventTypeNewBotSubscriber] = route(fn)
+}
+
+// OnPrivateMessage registers the callback for the message_from_bot_subscriber event.
+func (r *EventRouter) OnPrivateMessage(fn func(ctx context.Context, event PrivateMessageEvent) error) {
+	r.routes[EventTypeMessageFromBotSubscriber] = route(fn)
+}
+
+// OnGroupMention registers the callback for the new_mentioned_message_received_from_group_chat event.
+func (r *EventRouter) OnGroupMention(fn func(ctx context.Context, event GroupMentionEvent) error) {
+	r.routes[EventTypeNewMentionedMessageFromGroupChat] = route(fn)
+}
+
+// OnBotAddedToGroup registers the callback for the bot_added_to_group_chat event.
+func (r *EventRouter) OnBotAddedToGroup(fn func(ctx context.Context, event BotAddedToGroupEvent) error) {
+	r.routes[EventTypeBotAddedToGroupChat] = route(fn)
+}
+
+// OnBotRemovedFromGroup registers the callback for the bot_removed_from_group_chat event.
+func (r *EventRouter) OnBotRemovedFromGroup(fn func(ctx context.Context, event BotRemovedFromGroupEvent) error) {
+	r.routes[EventTypeBotRemovedFromGroupChat] = route(fn)
+}
+
+// OnInteractiveClick registers the callback for the interactive_message_click event.
+func (r *EventRouter) OnInteractiveClick(fn func(ctx context.Context, event InteractiveClickEvent) error) {
+	r.routes[EventTypeInteractiveMessageClick] = route(fn)
+}
+
+// Fallback registers the listener for the events without a registered callback.
+func (r *EventRouter) Fallback(listener EventListener) {
+	r.fallback = listener
+}
+
+// HandleEvent implements EventListener
+func (r *EventRouter) HandleEvent(ctx context.Context, event Event) error {
+	if fn, ok := r.routes[event.EventType]; ok {
+		return fn(ctx, event)
+	}
+
+	if r.fallback != nil {
+		return r.fallback.HandleEvent(ctx, event)
+	}
+
+	return nil
+}
+
+// route returns an EventListenerFunc that decodes the event payload into T before calling fn.
+func route[T any](fn func(ctx context.Context, event T) error) EventListenerFunc {
+	return func(ctx context.Context, event Event) error {
+		payload, err := helper.UnmarshalJSON[T](event.Event)
+		if err != nil {
+			return fmt.Errorf("can't decode %s event, %w", event.EventType, err)
+		}
+
+		return fn(ctx, payload)
+	}
+}
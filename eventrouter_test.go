@@ -0,0 +1,94 @@
+package seatalkbot
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_EventRouter_HandleEvent(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name       string
+		event      Event
+		wantCalled string
+		checkError require.ErrorAssertionFunc
+	}{
+		{
+			name: "it should call OnPrivateMessage callback for message_from_bot_subscriber event",
+			event: Event{
+				EventType: EventTypeMessageFromBotSubscriber,
+				Event:     []byte(`{"employee_code":"123","message":{"message_id":"m1","tag":"text","text":{"content":"hello"}}}`),
+			},
+			wantCalled: "private:123:hello",
+			checkError: require.NoError,
+		},
+		{
+			name: "it should call OnGroupMention callback for new_mentioned_message_received_from_group_chat event",
+			event: Event{
+				EventType: EventTypeNewMentionedMessageFromGroupChat,
+				Event:     []byte(`{"group_id":"g1","message":{"sender":{"employee_code":"123"},"tag":"text","text":{"plain_text":"@bot hi"}}}`),
+			},
+			wantCalled: "mention:g1:123:@bot hi",
+			checkError: require.NoError,
+		},
+		{
+			name: "it should call OnInteractiveClick callback for interactive_message_click event",
+			event: Event{
+				EventType: EventTypeInteractiveMessageClick,
+				Event:     []byte(`{"message_id":"m1","employee_code":"123","value":"approve"}`),
+			},
+			wantCalled: "click:m1:approve",
+			checkError: require.NoError,
+		},
+		{
+			name: "it should call fallback for unknown event type",
+			event: Event{
+				EventType: "some_new_event",
+				Event:     []byte(`{}`),
+			},
+			wantCalled: "fallback:some_new_event",
+			checkError: require.NoError,
+		},
+		{
+			name: "it should return error when payload can't be decoded",
+			event: Event{
+				EventType: EventTypeMessageFromBotSubscriber,
+				Event:     []byte(`{"employee_code":123}`),
+			},
+			checkError: require.Error,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var called string
+
+			router := NewEventRouter()
+			router.OnPrivateMessage(func(ctx context.Context, event PrivateMessageEvent) error {
+				called = "private:" + event.EmployeeCode + ":" + event.Message.Text.Content
+				return nil
+			})
+			router.OnGroupMention(func(ctx context.Context, event GroupMentionEvent) error {
+				called = "mention:" + event.GroupID + ":" + event.Message.Sender.EmployeeCode + ":" + event.Message.Text.PlainText
+				return nil
+			})
+			router.OnInteractiveClick(func(ctx context.Context, event InteractiveClickEvent) error {
+				called = "click:" + event.MessageID + ":" + event.Value
+				return nil
+			})
+			router.Fallback(EventListenerFunc(func(ctx context.Context, event Event) error {
+				called = "fallback:" + event.EventType
+				return nil
+			}))
+
+			err := router.HandleEvent(context.Background(), tt.event)
+
+			tt.checkError(t, err)
+			assert.Equal(t, tt.wantCalled, called)
+		})
+	}
+}
@@ -47,12 +47,22 @@ func main() {
 
 	fmt.Println("Group IDs:", groupIDs)
 
+	recipients := make([]seatalkbot.Recipient, 0, len(groupIDs))
 	for _, groupID := range groupIDs {
-		messageID, err := client.SendGroupMessage(context.Background(), groupID, seatalkbot.TextMessage("test message", ""))
-		if err != nil {
-			panic(err)
+		recipients = append(recipients, seatalkbot.GroupRecipient(groupID))
+	}
+
+	report, err := client.Broadcast(context.Background(), recipients, seatalkbot.TextMessage("test message", ""), seatalkbot.BroadcastOptions{})
+	if err != nil {
+		panic(err)
+	}
+
+	for _, result := range report.Results {
+		if result.Err != nil {
+			fmt.Printf("Failed to send message. group_id: %s, attempts: %d, error: %v\n", result.Recipient.GroupID, result.Attempts, result.Err)
+			continue
 		}
-		fmt.Printf("Message sent successfully. group_id: %s, message_id: %s", groupID, messageID)
+		fmt.Printf("Message sent successfully. group_id: %s, message_id: %s\n", result.Recipient.GroupID, result.MessageID)
 	}
 
 	fmt.Println("Done")
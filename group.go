@@ -0,0 +1,17 @@
+package seatalkbot
+
+// GroupInfo is the information of a group chat joined by the bot.
+type GroupInfo struct {
+	GroupID     string
+	GroupName   string
+	MemberCount int
+	Settings    GroupSettings
+}
+
+// GroupSettings is the settings of a group chat.
+type GroupSettings struct {
+	// ChatHistoryForNewMembers is the chat history visible to the new members, the value is defined by seatalk.
+	ChatHistoryForNewMembers int  `json:"chat_history_for_new_members"`
+	CanNotifyWithAtAll       bool `json:"can_notify_with_at_all"`
+	CanViewMemberList        bool `json:"can_view_member_list"`
+}
@@ -0,0 +1,66 @@
+package seatalkbot
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// Health is the status of the access token refreshes of a client.
+type Health struct {
+	// LastRefresh is the time of the last successful refresh, it's zero before the first one.
+	LastRefresh time.Time
+	// Expiry is the expiry of the current access token, it's zero before the first successful refresh and after
+	// the access token is rejected by seatalk and can't be refreshed.
+	Expiry time.Time
+	// ConsecutiveFailures is the number of refreshes failed since the last successful one.
+	ConsecutiveFailures int
+	// LastError is the error of the last failed refresh, it's kept after a successful refresh.
+	LastError error
+}
+
+// Healthy reports whether the client has an access token that has not expired nor been rejected by seatalk,
+// i.e. whether it can call the API.
+func (h Health) Healthy() bool {
+	return time.Now().Before(h.Expiry)
+}
+
+// healthRespBody is the response body of the handler returned by HealthHandler.
+type healthRespBody struct {
+	Status              string     `json:"status"`
+	LastRefresh         *time.Time `json:"last_refresh,omitempty"`
+	Expiry              *time.Time `json:"expiry,omitempty"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	LastError           string     `json:"last_error,omitempty"`
+}
+
+// HealthHandler returns an http.Handler reporting the Health of the client as json, to be used as a readiness
+// probe. It responds with the status code 200 when the client is healthy, and 503 otherwise.
+// A client created with WithLazyInit is not healthy until its first access token is fetched.
+func HealthHandler(c Client) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		health := c.Health()
+
+		body := healthRespBody{
+			Status:              "ok",
+			ConsecutiveFailures: health.ConsecutiveFailures,
+		}
+		if !health.LastRefresh.IsZero() {
+			body.LastRefresh = &health.LastRefresh
+			body.Expiry = &health.Expiry
+		}
+		if health.LastError != nil {
+			body.LastError = health.LastError.Error()
+		}
+
+		statusCode := http.StatusOK
+		if !health.Healthy() {
+			body.Status = "unhealthy"
+			statusCode = http.StatusServiceUnavailable
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(statusCode)
+		_ = json.NewEncoder(w).Encode(body)
+	})
+}
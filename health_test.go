@@ -0,0 +1,125 @@
+package seatalkbot_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anandawira/seatalkbot"
+	"github.com/anandawira/seatalkbot/seatalkbottest"
+)
+
+func Test_client_Health(t *testing.T) {
+	t.Parallel()
+	s := seatalkbottest.NewServer()
+	defer s.Close()
+
+	var (
+		mu       sync.Mutex
+		failures []int
+	)
+
+	c, err := seatalkbot.NewClient(seatalkbot.Config{
+		Host:      s.URL,
+		AppID:     "app-id",
+		AppSecret: "app-secret",
+		OnTokenRefreshError: func(err error, health seatalkbot.Health) {
+			mu.Lock()
+			defer mu.Unlock()
+
+			failures = append(failures, health.ConsecutiveFailures)
+		},
+	})
+	require.NoError(t, err)
+	defer c.Close()
+
+	health := c.Health()
+	assert.True(t, health.Healthy())
+	assert.WithinDuration(t, time.Now(), health.LastRefresh, time.Second)
+	assert.WithinDuration(t, time.Now().Add(2*time.Hour), health.Expiry, time.Second)
+
+	s.FailNext("/auth/app_access_token", seatalkbottest.Fault{StatusCode: http.StatusInternalServerError})
+	s.FailNext("/auth/app_access_token", seatalkbottest.Fault{StatusCode: http.StatusInternalServerError})
+
+	require.Error(t, c.UpdateAccessToken(context.Background()))
+	require.Error(t, c.UpdateAccessToken(context.Background()))
+
+	health = c.Health()
+	assert.True(t, health.Healthy(), "it should be healthy while the access token is valid")
+	assert.Equal(t, 2, health.ConsecutiveFailures)
+	assert.Error(t, health.LastError)
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []int{1, 2}, failures)
+}
+
+func Test_client_Health_rejectedToken(t *testing.T) {
+	t.Parallel()
+	s := seatalkbottest.NewServer()
+	defer s.Close()
+
+	c, err := seatalkbot.NewClient(seatalkbot.Config{Host: s.URL, AppID: "app-id", AppSecret: "app-secret"})
+	require.NoError(t, err)
+	defer c.Close()
+
+	require.True(t, c.Health().Healthy())
+
+	s.ExpireTokens()
+	s.FailNext("/auth/app_access_token", seatalkbottest.Fault{StatusCode: http.StatusInternalServerError})
+
+	require.Error(t, c.SendPrivateMessage(context.Background(), "123", seatalkbot.TextMessage("abc", "")))
+
+	health := c.Health()
+	assert.False(t, health.Healthy(), "it should not be healthy when the rejected access token can't be refreshed")
+	assert.Equal(t, 1, health.ConsecutiveFailures)
+	assert.Empty(t, c.AccessToken())
+
+	w := httptest.NewRecorder()
+	seatalkbot.HealthHandler(c).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/healthz", http.NoBody))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+}
+
+func Test_HealthHandler(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name           string
+		opts           []seatalkbot.Option
+		wantStatusCode int
+		wantStatus     string
+	}{
+		{
+			name:           "it should respond 200 when the client has a valid access token",
+			wantStatusCode: http.StatusOK,
+			wantStatus:     "ok",
+		},
+		{
+			name:           "it should respond 503 when the client has no access token",
+			opts:           []seatalkbot.Option{seatalkbot.WithLazyInit(), seatalkbot.WithoutAutoRefresh()},
+			wantStatusCode: http.StatusServiceUnavailable,
+			wantStatus:     "unhealthy",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			c, err := seatalkbot.NewClient(seatalkbot.Config{TokenSource: seatalkbot.StaticTokenSource("abc")}, tt.opts...)
+			require.NoError(t, err)
+			defer c.Close()
+
+			w := httptest.NewRecorder()
+			seatalkbot.HealthHandler(c).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/healthz", http.NoBody))
+
+			assert.Equal(t, tt.wantStatusCode, w.Code)
+			assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+			assert.Contains(t, w.Body.String(), `"status":"`+tt.wantStatus+`"`)
+		})
+	}
+}
@@ -1,9 +1,17 @@
 package helper
 
-import "time"
+import (
+	"context"
+	"math"
+	"math/rand"
+	"time"
+)
 
 // RunWithRetry runs the fn until it returns err nil or reaches the maxRetry.
 // If maxRetry is set to 0 or lower, it will keep retrying until success.
+//
+// Deprecated: the client retries the API calls with seatalkbot.RetryPolicy, which backs off and stops when the
+// context is done.
 func RunWithRetry(fn func() error, maxRetry int, interval time.Duration) error {
 	var i int
 
@@ -22,3 +30,43 @@ func RunWithRetry(fn func() error, maxRetry int, interval time.Duration) error {
 		time.Sleep(interval) // wait before retrying
 	}
 }
+
+// Backoff returns the duration to wait before the next attempt. It doubles the initial duration on every attempt
+// up to max, and picks a random duration between half of it and the full of it (equal jitter).
+// A zero or negative max doesn't cap the duration.
+func Backoff(attempt int, initial, max time.Duration) time.Duration {
+	if max <= 0 {
+		max = math.MaxInt64
+	}
+
+	d := initial
+	for i := 1; i < attempt && d < max; i++ {
+		if d > max/2 {
+			d = max
+			break
+		}
+		d *= 2
+	}
+	if d > max {
+		d = max
+	}
+	if d <= 0 {
+		return 0
+	}
+
+	half := d / 2
+	return half + time.Duration(rand.Int63n(int64(d-half)+1))
+}
+
+// Sleep waits for the duration, it returns ctx.Err() when ctx is done before.
+func Sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,242 @@
+package seatalkbot
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"unicode/utf8"
+)
+
+const (
+	// maxInteractiveElements is the maximum number of elements in an interactive message.
+	maxInteractiveElements = 20
+	// maxButtonGroupSize is the maximum number of buttons in a button group.
+	maxButtonGroupSize = 3
+	// maxTitleLength is the maximum number of characters of a title element.
+	maxTitleLength = 200
+	// maxDescriptionLength is the maximum number of characters of a description element.
+	maxDescriptionLength = 1000
+	// maxButtonTextLength is the maximum number of characters of a button text.
+	maxButtonTextLength = 20
+	// maxButtonValueLength is the maximum number of characters of a callback button value.
+	maxButtonValueLength = 200
+)
+
+// Button is a button of an interactive message. It's created by CallbackButton or RedirectButton.
+type Button struct {
+	button interactiveButton
+}
+
+// CallbackButton returns a button that triggers the interactive_message_click event with the value when clicked.
+func CallbackButton(text, value string) Button {
+	return Button{button: interactiveButton{
+		ButtonType: "callback",
+		Text:       text,
+		Value:      value,
+	}}
+}
+
+// RedirectButton returns a button that opens the url on both desktop and mobile when clicked.
+func RedirectButton(text, url string) Button {
+	link := &interactiveLink{Type: "web", Path: url}
+
+	return Button{button: interactiveButton{
+		ButtonType:  "redirect",
+		Text:        text,
+		MobileLink:  link,
+		DesktopLink: link,
+	}}
+}
+
+// InteractiveMessageBuilder builds an interactive message. The elements are shown in the order they're added.
+type InteractiveMessageBuilder struct {
+	elements []interactiveElement
+}
+
+// NewInteractiveMessage returns an empty InteractiveMessageBuilder.
+func NewInteractiveMessage() *InteractiveMessageBuilder {
+	return &InteractiveMessageBuilder{}
+}
+
+// Title adds a title element.
+func (b *InteractiveMessageBuilder) Title(text string) *InteractiveMessageBuilder {
+	b.elements = append(b.elements, interactiveElement{
+		ElementType: "title",
+		Title:       &interactiveText{Text: text},
+	})
+	return b
+}
+
+// Description adds a description element.
+func (b *InteractiveMessageBuilder) Description(text string) *InteractiveMessageBuilder {
+	b.elements = append(b.elements, interactiveElement{
+		ElementType: "description",
+		Description: &interactiveText{Text: text},
+	})
+	return b
+}
+
+// Image adds an image element, the content is the raw bytes of the image.
+func (b *InteractiveMessageBuilder) Image(content []byte) *InteractiveMessageBuilder {
+	b.elements = append(b.elements, interactiveElement{
+		ElementType: "image",
+		Image:       &interactiveImage{raw: content},
+	})
+	return b
+}
+
+// Button adds a single button element.
+func (b *InteractiveMessageBuilder) Button(button Button) *InteractiveMessageBuilder {
+	b.elements = append(b.elements, interactiveElement{
+		ElementType: "button",
+		Button:      &button.button,
+	})
+	return b
+}
+
+// ButtonGroup adds a row of buttons.
+func (b *InteractiveMessageBuilder) ButtonGroup(buttons ...Button) *InteractiveMessageBuilder {
+	group := make([]interactiveButton, 0, len(buttons))
+	for _, button := range buttons {
+		group = append(group, button.button)
+	}
+
+	b.elements = append(b.elements, interactiveElement{
+		ElementType: "button_group",
+		ButtonGroup: group,
+	})
+	return b
+}
+
+// Build validates the elements and returns the interactive message.
+func (b *InteractiveMessageBuilder) Build() (Message, error) {
+	if len(b.elements) == 0 {
+		return nil, errors.New("interactive message should have at least 1 element")
+	}
+	if len(b.elements) > maxInteractiveElements {
+		return nil, fmt.Errorf("interactive message should have at most %d elements, got: %d", maxInteractiveElements, len(b.elements))
+	}
+
+	m := interactiveMessage{Tag: "interactive_message"}
+	m.InteractiveMessage.Elements = make([]interactiveElement, 0, len(b.elements))
+
+	for i, element := range b.elements {
+		if err := element.validate(); err != nil {
+			return nil, fmt.Errorf("invalid element %d, %w", i, err)
+		}
+
+		if element.Image != nil {
+			element.Image = &interactiveImage{Content: base64.StdEncoding.EncodeToString(element.Image.raw)}
+		}
+
+		m.InteractiveMessage.Elements = append(m.InteractiveMessage.Elements, element)
+	}
+
+	return m, nil
+}
+
+type interactiveMessage struct {
+	Tag                string `json:"tag"`
+	InteractiveMessage struct {
+		Elements []interactiveElement `json:"elements"`
+	} `json:"interactive_message"`
+}
+
+func (m interactiveMessage) Message() json.RawMessage {
+	b, err := json.Marshal(m)
+
+	if err != nil {
+		panic(err)
+	}
+
+	return b
+}
+
+type interactiveElement struct {
+	ElementType string              `json:"element_type"`
+	Title       *interactiveText    `json:"title,omitempty"`
+	Description *interactiveText    `json:"description,omitempty"`
+	Image       *interactiveImage   `json:"image,omitempty"`
+	Button      *interactiveButton  `json:"button,omitempty"`
+	ButtonGroup []interactiveButton `json:"button_group,omitempty"`
+}
+
+func (e interactiveElement) validate() error {
+	switch e.ElementType {
+	case "title":
+		return validateLength("title", e.Title.Text, maxTitleLength)
+	case "description":
+		return validateLength("description", e.Description.Text, maxDescriptionLength)
+	case "image":
+		return validateImage(e.Image.raw)
+	case "button":
+		return e.Button.validate()
+	case "button_group":
+		if len(e.ButtonGroup) == 0 || len(e.ButtonGroup) > maxButtonGroupSize {
+			return fmt.Errorf("button group should have 1 to %d buttons, got: %d", maxButtonGroupSize, len(e.ButtonGroup))
+		}
+		for _, button := range e.ButtonGroup {
+			if err := button.validate(); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown element type: %s", e.ElementType)
+	}
+}
+
+type interactiveText struct {
+	Text string `json:"text"`
+}
+
+type interactiveImage struct {
+	Content string `json:"content"`
+
+	raw []byte
+}
+
+type interactiveButton struct {
+	ButtonType  string           `json:"button_type"`
+	Text        string           `json:"text"`
+	Value       string           `json:"value,omitempty"`
+	MobileLink  *interactiveLink `json:"mobile_link,omitempty"`
+	DesktopLink *interactiveLink `json:"desktop_link,omitempty"`
+}
+
+func (b interactiveButton) validate() error {
+	if err := validateLength("button text", b.Text, maxButtonTextLength); err != nil {
+		return err
+	}
+
+	switch b.ButtonType {
+	case "callback":
+		return validateLength("button value", b.Value, maxButtonValueLength)
+	case "redirect":
+		if b.DesktopLink == nil || b.DesktopLink.Path == "" {
+			return errors.New("redirect button link should not be empty")
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown button type: %s", b.ButtonType)
+	}
+}
+
+type interactiveLink struct {
+	Type string `json:"type"`
+	Path string `json:"path"`
+}
+
+// validateLength checks that the value is not empty and has at most maxLength characters.
+func validateLength(field, value string, maxLength int) error {
+	length := utf8.RuneCountInString(value)
+	if length == 0 {
+		return fmt.Errorf("%s should not be empty", field)
+	}
+	if length > maxLength {
+		return fmt.Errorf("%s should be at most %d characters, got: %d", field, maxLength, length)
+	}
+
+	return nil
+}
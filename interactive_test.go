@@ -0,0 +1,81 @@
+package seatalkbot
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_InteractiveMessageBuilder_Build(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name        string
+		builder     *InteractiveMessageBuilder
+		checkError  require.ErrorAssertionFunc
+		wantMessage string
+	}{
+		{
+			name:       "it should return error when there is no element",
+			builder:    NewInteractiveMessage(),
+			checkError: require.Error,
+		},
+		{
+			name: "it should return error when there are too many elements",
+			builder: func() *InteractiveMessageBuilder {
+				b := NewInteractiveMessage()
+				for i := 0; i <= maxInteractiveElements; i++ {
+					b.Title("a")
+				}
+				return b
+			}(),
+			checkError: require.Error,
+		},
+		{
+			name:       "it should return error when title is too long",
+			builder:    NewInteractiveMessage().Title(strings.Repeat("a", maxTitleLength+1)),
+			checkError: require.Error,
+		},
+		{
+			name:       "it should return error when button group has too many buttons",
+			builder:    NewInteractiveMessage().ButtonGroup(CallbackButton("a", "a"), CallbackButton("b", "b"), CallbackButton("c", "c"), CallbackButton("d", "d")),
+			checkError: require.Error,
+		},
+		{
+			name:       "it should return error when callback button value is empty",
+			builder:    NewInteractiveMessage().Button(CallbackButton("Approve", "")),
+			checkError: require.Error,
+		},
+		{
+			name: "it should return the message when all elements are valid",
+			builder: NewInteractiveMessage().
+				Title("Leave request").
+				Description("John requested 2 days of leave").
+				Image(testPNG).
+				ButtonGroup(CallbackButton("Approve", "approve:1"), RedirectButton("Detail", "https://example.com/1")),
+			checkError: require.NoError,
+			wantMessage: `{"tag":"interactive_message","interactive_message":{"elements":[
+				{"element_type":"title","title":{"text":"Leave request"}},
+				{"element_type":"description","description":{"text":"John requested 2 days of leave"}},
+				{"element_type":"image","image":{"content":"iVBORw0KGgo="}},
+				{"element_type":"button_group","button_group":[
+					{"button_type":"callback","text":"Approve","value":"approve:1"},
+					{"button_type":"redirect","text":"Detail","mobile_link":{"type":"web","path":"https://example.com/1"},"desktop_link":{"type":"web","path":"https://example.com/1"}}
+				]}
+			]}}`,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			message, err := tt.builder.Build()
+
+			tt.checkError(t, err)
+			if err == nil {
+				assert.JSONEq(t, tt.wantMessage, string(message.Message()))
+			}
+		})
+	}
+}
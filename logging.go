@@ -0,0 +1,68 @@
+package seatalkbot
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"log/slog"
+)
+
+// redacted replaces the secrets in the logs.
+const redacted = "[REDACTED]"
+
+// discardHandler is a slog.Handler that drops every record, it's the handler of the default logger.
+type discardHandler struct{}
+
+func (discardHandler) Enabled(context.Context, slog.Level) bool  { return false }
+func (discardHandler) Handle(context.Context, slog.Record) error { return nil }
+func (h discardHandler) WithAttrs([]slog.Attr) slog.Handler      { return h }
+func (h discardHandler) WithGroup(string) slog.Handler           { return h }
+
+// LogValue implements slog.LogValuer, the AppSecret is redacted.
+func (config Config) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("host", config.Host),
+		slog.String("app_id", config.AppID),
+		slog.String("app_secret", redacted),
+	)
+}
+
+// LogValue implements slog.LogValuer, the AccessToken is replaced by its fingerprint.
+func (t Token) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("fingerprint", fingerprint(t.AccessToken)),
+		slog.Time("expiry", t.Expiry),
+	)
+}
+
+// fingerprint returns a short hash of the secret, to tell the secrets apart in the logs without leaking them.
+func fingerprint(secret string) string {
+	if secret == "" {
+		return ""
+	}
+
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:4])
+}
+
+type recipientKey struct{}
+
+// withRecipient returns a copy of ctx carrying the recipient of the message sent with it, so it's logged along with
+// the API call.
+func withRecipient(ctx context.Context, recipient Recipient) context.Context {
+	return context.WithValue(ctx, recipientKey{}, recipient)
+}
+
+// recipientAttrs returns the log attributes of the recipient carried by ctx, if any.
+func recipientAttrs(ctx context.Context) []any {
+	recipient, ok := ctx.Value(recipientKey{}).(Recipient)
+	if !ok {
+		return nil
+	}
+
+	if recipient.GroupID != "" {
+		return []any{slog.String("group_id", recipient.GroupID)}
+	}
+
+	return []any{slog.String("employee_code", recipient.EmployeeCode)}
+}
@@ -0,0 +1,127 @@
+package seatalkbot_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anandawira/seatalkbot"
+	"github.com/anandawira/seatalkbot/seatalkbottest"
+)
+
+// syncBuffer is a bytes.Buffer safe for concurrent writes.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.Write(p)
+}
+
+// records returns the logged records decoded from json.
+func (b *syncBuffer) records(t *testing.T) []map[string]any {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var records []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(b.buf.String()), "\n") {
+		var record map[string]any
+		require.NoError(t, json.Unmarshal([]byte(line), &record))
+		records = append(records, record)
+	}
+
+	return records
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.String()
+}
+
+func Test_client_logging(t *testing.T) {
+	t.Parallel()
+	s := seatalkbottest.NewServer()
+	defer s.Close()
+
+	s.FailNext("/messaging/v2/single_chat", seatalkbottest.Fault{Code: seatalkbot.CodeAccessTokenInvalid})
+	s.RateLimitNext("/messaging/v2/single_chat", 0)
+	s.FailNext("/messaging/v2/single_chat", seatalkbottest.Fault{Code: seatalkbot.CodeUserNotFound})
+
+	logs := &syncBuffer{}
+
+	c, err := seatalkbot.NewClient(seatalkbot.Config{
+		Host:      s.URL,
+		AppID:     "app-id",
+		AppSecret: "super-secret",
+		Logger:    slog.New(slog.NewJSONHandler(logs, &slog.HandlerOptions{Level: slog.LevelDebug})),
+		RetryPolicy: &seatalkbot.RetryPolicy{
+			MaxAttempts:    3,
+			InitialBackoff: time.Millisecond,
+			MaxBackoff:     time.Millisecond,
+		},
+	})
+	require.NoError(t, err)
+	defer c.Close()
+
+	firstToken := c.AccessToken()
+
+	err = c.SendPrivateMessage(context.Background(), "150001", seatalkbot.TextMessage("abc", ""))
+	require.True(t, seatalkbot.IsUserNotFound(err))
+
+	var messages []string
+	for _, record := range logs.records(t) {
+		messages = append(messages, record["msg"].(string))
+
+		if record["msg"] == "api call failed" {
+			assert.Equal(t, "/messaging/v2/single_chat", record["endpoint"])
+			assert.Equal(t, "150001", record["employee_code"])
+			assert.Contains(t, record, "code")
+			assert.Contains(t, record, "latency")
+		}
+	}
+
+	assert.Equal(t, []string{
+		"api call succeeded",
+		"access token refreshed",
+		"api call failed",
+		"access token rejected",
+		"api call succeeded",
+		"access token refreshed",
+		"api call failed",
+		"retrying api call",
+		"api call failed",
+	}, messages)
+
+	for _, secret := range []string{"super-secret", firstToken, c.AccessToken()} {
+		assert.NotContains(t, logs.String(), secret)
+	}
+}
+
+func Test_Config_LogValue(t *testing.T) {
+	t.Parallel()
+	logs := &syncBuffer{}
+
+	slog.New(slog.NewJSONHandler(logs, nil)).Info("config",
+		slog.Any("config", seatalkbot.Config{AppID: "app-id", AppSecret: "super-secret"}),
+		slog.Any("token", seatalkbot.Token{AccessToken: "access-token"}),
+	)
+
+	assert.Contains(t, logs.String(), "app-id")
+	assert.NotContains(t, logs.String(), "super-secret")
+	assert.NotContains(t, logs.String(), "access-token")
+	assert.Contains(t, logs.String(), `"fingerprint":"`)
+}
@@ -0,0 +1,168 @@
+package seatalkbot
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// textFormatMarkdown is the text format rendering the content as markdown.
+const textFormatMarkdown = 1
+
+// markdownEscaper escapes the characters having a meaning in markdown.
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`, "`", "\\`", `*`, `\*`, `_`, `\_`, `~`, `\~`,
+	`[`, `\[`, `]`, `\]`, `(`, `\(`, `)`, `\)`, `<`, `\<`, `>`, `\>`,
+	`#`, `\#`, `+`, `\+`, `-`, `\-`, `.`, `\.`, `!`, `\!`, `|`, `\|`, `=`, `\=`,
+)
+
+// lineBreakReplacer replaces the line breaks by spaces, to keep a text on a single line.
+var lineBreakReplacer = strings.NewReplacer("\r\n", " ", "\n", " ", "\r", " ")
+
+// markdownURLEscaper percent-encodes the characters that would end the link destination.
+var markdownURLEscaper = strings.NewReplacer(" ", "%20", "(", "%28", ")", "%29", "<", "%3C", ">", "%3E")
+
+// linkSchemes are the url schemes allowed in a link.
+var linkSchemes = map[string]bool{
+	"http":   true,
+	"https":  true,
+	"mailto": true,
+}
+
+// MarkdownMessage returns a text message rendered as markdown. The content is sent as is, use MarkdownBuilder
+// to build the content from user-provided strings.
+func MarkdownMessage(content, quotedMessageID string) Message {
+	m := textMessage{
+		Tag:             "text",
+		QuotedMessageID: quotedMessageID,
+	}
+	m.Text.Format = textFormatMarkdown
+	m.Text.Content = content
+
+	return m
+}
+
+// MarkdownBuilder builds a markdown content. Every string passed to it is escaped, so it can't break out of
+// the formatting applied by the builder.
+type MarkdownBuilder struct {
+	sb strings.Builder
+}
+
+// NewMarkdown returns an empty MarkdownBuilder.
+func NewMarkdown() *MarkdownBuilder {
+	return &MarkdownBuilder{}
+}
+
+// Text adds a plain text.
+func (m *MarkdownBuilder) Text(text string) *MarkdownBuilder {
+	m.sb.WriteString(EscapeMarkdown(text))
+	return m
+}
+
+// Bold adds a bold text. Line breaks in the text are replaced by spaces.
+func (m *MarkdownBuilder) Bold(text string) *MarkdownBuilder {
+	m.sb.WriteString("**" + escapeInline(text) + "**")
+	return m
+}
+
+// Italic adds an italic text. Line breaks in the text are replaced by spaces.
+func (m *MarkdownBuilder) Italic(text string) *MarkdownBuilder {
+	m.sb.WriteString("*" + escapeInline(text) + "*")
+	return m
+}
+
+// Link adds a link with the text pointing to the url. Only http, https and mailto urls are linked,
+// otherwise only the text is added. Line breaks in the text are replaced by spaces.
+func (m *MarkdownBuilder) Link(text, link string) *MarkdownBuilder {
+	u, err := url.Parse(strings.TrimSpace(link))
+	if err != nil || !linkSchemes[strings.ToLower(u.Scheme)] {
+		return m.Text(lineBreakReplacer.Replace(text))
+	}
+
+	m.sb.WriteString("[" + escapeInline(text) + "](" + markdownURLEscaper.Replace(u.String()) + ")")
+	return m
+}
+
+// Code adds an inline code. Line breaks in the code are replaced by spaces.
+func (m *MarkdownBuilder) Code(code string) *MarkdownBuilder {
+	code = lineBreakReplacer.Replace(code)
+	fence := codeFence(code, 1)
+	m.sb.WriteString(fence + " " + code + " " + fence)
+	return m
+}
+
+// CodeBlock adds a code block on its own lines.
+func (m *MarkdownBuilder) CodeBlock(code string) *MarkdownBuilder {
+	fence := codeFence(code, 3)
+	m.startBlock()
+	m.sb.WriteString(fence + "\n" + code + "\n" + fence + "\n")
+	return m
+}
+
+// List adds a bulleted list, one item per line. Line breaks in the items are replaced by spaces.
+func (m *MarkdownBuilder) List(items ...string) *MarkdownBuilder {
+	m.startBlock()
+	for _, item := range items {
+		m.sb.WriteString("- " + escapeInline(item) + "\n")
+	}
+	return m
+}
+
+// OrderedList adds a numbered list, one item per line. Line breaks in the items are replaced by spaces.
+func (m *MarkdownBuilder) OrderedList(items ...string) *MarkdownBuilder {
+	m.startBlock()
+	for i, item := range items {
+		m.sb.WriteString(strconv.Itoa(i+1) + ". " + escapeInline(item) + "\n")
+	}
+	return m
+}
+
+// Line ends the current line.
+func (m *MarkdownBuilder) Line() *MarkdownBuilder {
+	m.sb.WriteString("\n")
+	return m
+}
+
+// String returns the markdown content.
+func (m *MarkdownBuilder) String() string {
+	return m.sb.String()
+}
+
+// Build returns the markdown content as a message.
+func (m *MarkdownBuilder) Build(quotedMessageID string) Message {
+	return MarkdownMessage(m.String(), quotedMessageID)
+}
+
+// startBlock makes sure the next block starts on a new line.
+func (m *MarkdownBuilder) startBlock() {
+	if s := m.sb.String(); s != "" && !strings.HasSuffix(s, "\n") {
+		m.sb.WriteString("\n")
+	}
+}
+
+// EscapeMarkdown escapes the text so it's rendered literally in a markdown message.
+func EscapeMarkdown(text string) string {
+	return markdownEscaper.Replace(text)
+}
+
+// escapeInline escapes the text and puts it on a single line, so it can't break out of an inline formatting or
+// a list item.
+func escapeInline(text string) string {
+	return EscapeMarkdown(lineBreakReplacer.Replace(text))
+}
+
+// codeFence returns a backtick fence of at least minLength that is longer than any backtick run in the code,
+// so the code can't close the fence.
+func codeFence(code string, minLength int) string {
+	longest, current := 0, 0
+	for _, r := range code {
+		if r == '`' {
+			current++
+			longest = max(longest, current)
+		} else {
+			current = 0
+		}
+	}
+
+	return strings.Repeat("`", max(minLength, longest+1))
+}
@@ -0,0 +1,86 @@
+package seatalkbot
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_MarkdownBuilder(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		builder *MarkdownBuilder
+		want    string
+	}{
+		{
+			name:    "it should escape markdown characters in text",
+			builder: NewMarkdown().Text("*not bold* [x](y)"),
+			want:    `\*not bold\* \[x\]\(y\)`,
+		},
+		{
+			name:    "it should keep user input inside the bold formatting",
+			builder: NewMarkdown().Bold("a** b").Text(" c"),
+			want:    `**a\*\* b** c`,
+		},
+		{
+			name:    "it should keep bold text with line breaks in a single paragraph",
+			builder: NewMarkdown().Bold("evil\n\nbreak").Italic("a\r\nb"),
+			want:    `**evil  break***a b*`,
+		},
+		{
+			name:    "it should escape the setext heading underline",
+			builder: NewMarkdown().Text("Heading\n==="),
+			want:    "Heading\n\\=\\=\\=",
+		},
+		{
+			name:    "it should keep list items with line breaks on a single line",
+			builder: NewMarkdown().List("item\n\n    indented code").OrderedList("a\nb"),
+			want:    "- item      indented code\n1. a b\n",
+		},
+		{
+			name:    "it should keep link text with line breaks on a single line",
+			builder: NewMarkdown().Link("a\n\nb", "https://example.com"),
+			want:    `[a  b](https://example.com)`,
+		},
+		{
+			name:    "it should escape link text and url",
+			builder: NewMarkdown().Link("see [here]", "https://example.com/a (b)"),
+			want:    `[see \[here\]](https://example.com/a%20%28b%29)`,
+		},
+		{
+			name:    "it should add only the text when link scheme is not allowed",
+			builder: NewMarkdown().Link("click", "javascript:alert(1)"),
+			want:    `click`,
+		},
+		{
+			name:    "it should use a fence longer than the backticks in the code",
+			builder: NewMarkdown().Text("Error:").CodeBlock("a ``` b"),
+			want:    "Error:\n````\na ``` b\n````\n",
+		},
+		{
+			name:    "it should put inline code on a single line",
+			builder: NewMarkdown().Code("a `b`\nc"),
+			want:    "`` a `b` c ``",
+		},
+		{
+			name:    "it should add lists on their own lines",
+			builder: NewMarkdown().Bold("Affected:").List("svc-a", "svc-b").OrderedList("restart", "verify"),
+			want:    "**Affected:**\n- svc\\-a\n- svc\\-b\n1. restart\n2. verify\n",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.want, tt.builder.String())
+		})
+	}
+}
+
+func Test_MarkdownMessage(t *testing.T) {
+	t.Parallel()
+	message := NewMarkdown().Bold("Incident").Build("")
+
+	assert.JSONEq(t, `{"tag":"text","text":{"format":1,"content":"**Incident**"}}`, string(message.Message()))
+}
@@ -1,16 +1,56 @@
 package seatalkbot
 
-import "encoding/json"
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"unicode/utf8"
+)
+
+const (
+	// maxImageSize is the maximum size in bytes of an image before it's base64 encoded.
+	maxImageSize = 5 << 20
+	// maxFileSize is the maximum size in bytes of a file before it's base64 encoded.
+	maxFileSize = 5 << 20
+	// maxFilenameLength is the maximum number of characters of a file name, including the extension.
+	maxFilenameLength = 100
+)
+
+// supportedImageTypes are the image content types accepted by seatalk.
+var supportedImageTypes = map[string]bool{
+	"image/png":  true,
+	"image/jpeg": true,
+	"image/gif":  true,
+}
 
 // Message is used as a parameter for sending message
 type Message interface {
 	Message() json.RawMessage
 }
 
+// ContentTooLargeError is returned when the content of an image or a file exceeds the size limit of seatalk.
+type ContentTooLargeError struct {
+	// Size is the size of the content in bytes. When the content is read from an io.Reader, reading stops
+	// once the limit is exceeded, so Size is Limit + 1.
+	Size int
+	// Limit is the maximum size in bytes.
+	Limit int
+}
+
+func (e *ContentTooLargeError) Error() string {
+	return fmt.Sprintf("content size should be at most %d bytes, got: %d", e.Limit, e.Size)
+}
+
 func TextMessage(content, quotedMessageID string) Message {
 	return textMessage{
 		Tag: "text",
 		Text: struct {
+			Format  int    `json:"format,omitempty"`
 			Content string `json:"content"`
 		}{Content: content},
 		QuotedMessageID: quotedMessageID,
@@ -20,6 +60,7 @@ func TextMessage(content, quotedMessageID string) Message {
 type textMessage struct {
 	Tag  string `json:"tag"`
 	Text struct {
+		Format  int    `json:"format,omitempty"`
 		Content string `json:"content"`
 	} `json:"text"`
 	QuotedMessageID string `json:"quoted_message_id,omitempty"`
@@ -34,3 +75,148 @@ func (t textMessage) Message() json.RawMessage {
 
 	return b
 }
+
+// ImageMessage returns an image message with the content. The content must be a PNG, JPG or GIF image
+// of at most 5 MB, otherwise an error is returned.
+func ImageMessage(content []byte) (Message, error) {
+	if err := validateImage(content); err != nil {
+		return nil, err
+	}
+
+	m := imageMessage{Tag: "image"}
+	m.Image.Content = base64.StdEncoding.EncodeToString(content)
+
+	return m, nil
+}
+
+// ImageMessageFromReader reads the image from r and returns it as an image message. See ImageMessage.
+func ImageMessageFromReader(r io.Reader) (Message, error) {
+	content, err := readLimited(r, maxImageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	return ImageMessage(content)
+}
+
+type imageMessage struct {
+	Tag   string `json:"tag"`
+	Image struct {
+		Content string `json:"content"`
+	} `json:"image"`
+}
+
+func (i imageMessage) Message() json.RawMessage {
+	b, err := json.Marshal(i)
+
+	if err != nil {
+		panic(err)
+	}
+
+	return b
+}
+
+// FileMessage returns a file message with the filename and the content. The filename must have an extension
+// and the content must be at most 5 MB, otherwise an error is returned.
+func FileMessage(filename string, content []byte) (Message, error) {
+	if err := validateFilename(filename); err != nil {
+		return nil, err
+	}
+	if len(content) == 0 {
+		return nil, errors.New("file should not be empty")
+	}
+	if len(content) > maxFileSize {
+		return nil, &ContentTooLargeError{Size: len(content), Limit: maxFileSize}
+	}
+
+	m := fileMessage{Tag: "file"}
+	m.File.Filename = filename
+	m.File.Content = base64.StdEncoding.EncodeToString(content)
+
+	return m, nil
+}
+
+// FileMessageFromReader reads the file content from r and returns it as a file message. See FileMessage.
+func FileMessageFromReader(filename string, r io.Reader) (Message, error) {
+	content, err := readLimited(r, maxFileSize)
+	if err != nil {
+		return nil, err
+	}
+
+	return FileMessage(filename, content)
+}
+
+type fileMessage struct {
+	Tag  string `json:"tag"`
+	File struct {
+		Filename string `json:"filename"`
+		Content  string `json:"content"`
+	} `json:"file"`
+}
+
+func (f fileMessage) Message() json.RawMessage {
+	b, err := json.Marshal(f)
+
+	if err != nil {
+		panic(err)
+	}
+
+	return b
+}
+
+// withThreadID adds the thread_id field to the message, so it's sent into the thread instead of the main timeline.
+func withThreadID(message json.RawMessage, threadID string) (json.RawMessage, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(message, &fields); err != nil {
+		return nil, fmt.Errorf("message should be a json object, %w", err)
+	}
+
+	rawThreadID, err := json.Marshal(threadID)
+	if err != nil {
+		return nil, err
+	}
+
+	fields["thread_id"] = rawThreadID
+
+	return json.Marshal(fields)
+}
+
+func validateImage(content []byte) error {
+	if len(content) == 0 {
+		return errors.New("image should not be empty")
+	}
+	if len(content) > maxImageSize {
+		return &ContentTooLargeError{Size: len(content), Limit: maxImageSize}
+	}
+	if contentType := http.DetectContentType(content); !supportedImageTypes[contentType] {
+		return fmt.Errorf("image should be png, jpg or gif, got: %s", contentType)
+	}
+
+	return nil
+}
+
+func validateFilename(filename string) error {
+	if filepath.Ext(filename) == "" {
+		return fmt.Errorf("filename should have an extension, got: %q", filename)
+	}
+	if length := utf8.RuneCountInString(filename); length > maxFilenameLength {
+		return fmt.Errorf("filename should be at most %d characters, got: %d", maxFilenameLength, length)
+	}
+
+	return nil
+}
+
+// readLimited reads r until EOF. It returns ContentTooLargeError as soon as more than limit bytes are read.
+func readLimited(r io.Reader, limit int) ([]byte, error) {
+	var buf bytes.Buffer
+
+	n, err := buf.ReadFrom(io.LimitReader(r, int64(limit)+1))
+	if err != nil {
+		return nil, err
+	}
+	if n > int64(limit) {
+		return nil, &ContentTooLargeError{Size: int(n), Limit: limit}
+	}
+
+	return buf.Bytes(), nil
+}
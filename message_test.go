@@ -0,0 +1,112 @@
+package seatalkbot
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testPNG is the png signature, it's enough to be detected as a png image.
+var testPNG = []byte("\x89PNG\r\n\x1a\n")
+
+func Test_ImageMessageFromReader(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name         string
+		content      []byte
+		checkError   require.ErrorAssertionFunc
+		wantTooLarge bool
+		wantMessage  string
+	}{
+		{
+			name:       "it should return error when image is empty",
+			content:    nil,
+			checkError: require.Error,
+		},
+		{
+			name:       "it should return error when content is not a supported image",
+			content:    []byte("name,value\na,1\n"),
+			checkError: require.Error,
+		},
+		{
+			name:         "it should return ContentTooLargeError when image is larger than the limit",
+			content:      append(testPNG, make([]byte, maxImageSize)...),
+			checkError:   require.Error,
+			wantTooLarge: true,
+		},
+		{
+			name:        "it should return base64 encoded image message",
+			content:     testPNG,
+			checkError:  require.NoError,
+			wantMessage: `{"tag":"image","image":{"content":"iVBORw0KGgo="}}`,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			message, err := ImageMessageFromReader(bytes.NewReader(tt.content))
+
+			tt.checkError(t, err)
+
+			var tooLargeErr *ContentTooLargeError
+			assert.Equal(t, tt.wantTooLarge, errors.As(err, &tooLargeErr))
+
+			if err == nil {
+				assert.JSONEq(t, tt.wantMessage, string(message.Message()))
+			}
+		})
+	}
+}
+
+func Test_FileMessage(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name         string
+		filename     string
+		content      []byte
+		checkError   require.ErrorAssertionFunc
+		wantTooLarge bool
+		wantMessage  string
+	}{
+		{
+			name:       "it should return error when filename has no extension",
+			filename:   "report",
+			content:    []byte("abc"),
+			checkError: require.Error,
+		},
+		{
+			name:         "it should return ContentTooLargeError when file is larger than the limit",
+			filename:     "report.csv",
+			content:      make([]byte, maxFileSize+1),
+			checkError:   require.Error,
+			wantTooLarge: true,
+		},
+		{
+			name:        "it should return base64 encoded file message",
+			filename:    "report.csv",
+			content:     []byte("abc"),
+			checkError:  require.NoError,
+			wantMessage: `{"tag":"file","file":{"filename":"report.csv","content":"YWJj"}}`,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			message, err := FileMessage(tt.filename, tt.content)
+
+			tt.checkError(t, err)
+
+			var tooLargeErr *ContentTooLargeError
+			assert.Equal(t, tt.wantTooLarge, errors.As(err, &tooLargeErr))
+
+			if err == nil {
+				assert.JSONEq(t, tt.wantMessage, string(message.Message()))
+			}
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package seatalkbot
+
+import "time"
+
+// Metrics receives the measurements of the client, e.g. to export them to Prometheus.
+// The implementation must be safe for concurrent use, and should not block.
+type Metrics interface {
+	// ObserveAPICall is called after every HTTP call to the bot api, including the retries.
+	ObserveAPICall(call APICallMetric)
+	// ObserveTokenRefresh is called after every access token refresh.
+	ObserveTokenRefresh(refresh TokenRefreshMetric)
+}
+
+// APICallMetric is the measurement of an HTTP call to the bot api.
+type APICallMetric struct {
+	// Endpoint is the path of the API, e.g. /messaging/v2/single_chat.
+	Endpoint string
+	// StatusCode is the http status code, it's 0 when there is no response.
+	StatusCode int
+	// Code is the code in the response body, it's 0 when the call succeeds.
+	Code int
+	// Duration is the duration of the HTTP call, excluding the rate limiter wait.
+	Duration time.Duration
+	// Attempt is the number of the attempt, 1 for the first call and higher for the retries.
+	Attempt int
+	// Err is the error of the call, it's nil when the call succeeds.
+	Err error
+}
+
+// TokenRefreshMetric is the measurement of an access token refresh.
+type TokenRefreshMetric struct {
+	Duration time.Duration
+	// Err is the error of the refresh, it's nil when the refresh succeeds.
+	Err error
+}
+
+// noopMetrics is the Metrics used when none is set in the config.
+type noopMetrics struct{}
+
+func (noopMetrics) ObserveAPICall(APICallMetric)           {}
+func (noopMetrics) ObserveTokenRefresh(TokenRefreshMetric) {}
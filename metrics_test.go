@@ -0,0 +1,80 @@
+package seatalkbot_test
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anandawira/seatalkbot"
+	"github.com/anandawira/seatalkbot/seatalkbottest"
+)
+
+// recordingMetrics records the measurements.
+type recordingMetrics struct {
+	mu        sync.Mutex
+	calls     []seatalkbot.APICallMetric
+	refreshes []seatalkbot.TokenRefreshMetric
+}
+
+func (m *recordingMetrics) ObserveAPICall(call seatalkbot.APICallMetric) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.calls = append(m.calls, call)
+}
+
+func (m *recordingMetrics) ObserveTokenRefresh(refresh seatalkbot.TokenRefreshMetric) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.refreshes = append(m.refreshes, refresh)
+}
+
+func Test_client_metrics(t *testing.T) {
+	t.Parallel()
+	s := seatalkbottest.NewServer()
+	defer s.Close()
+
+	s.AddGroup("g1", "Group 1")
+	s.RateLimitNext("/messaging/v2/group_chat", 0)
+
+	metrics := &recordingMetrics{}
+
+	c, err := seatalkbot.NewClient(
+		seatalkbot.Config{AppID: "app-id", AppSecret: "app-secret"},
+		seatalkbot.WithHost(s.URL),
+		seatalkbot.WithMetrics(metrics),
+		seatalkbot.WithRetryPolicy(seatalkbot.RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond}),
+	)
+	require.NoError(t, err)
+	defer c.Close()
+
+	_, err = c.SendGroupMessage(context.Background(), "g1", seatalkbot.TextMessage("abc", ""))
+	require.NoError(t, err)
+
+	metrics.mu.Lock()
+	defer metrics.mu.Unlock()
+
+	require.Len(t, metrics.refreshes, 1)
+	assert.NoError(t, metrics.refreshes[0].Err)
+
+	require.Len(t, metrics.calls, 3)
+	assert.Equal(t, "/auth/app_access_token", metrics.calls[0].Endpoint)
+	assert.NoError(t, metrics.calls[0].Err)
+
+	assert.Equal(t, "/messaging/v2/group_chat", metrics.calls[1].Endpoint)
+	assert.Equal(t, http.StatusTooManyRequests, metrics.calls[1].StatusCode)
+	assert.Equal(t, seatalkbot.CodeRateLimited, metrics.calls[1].Code)
+	assert.Equal(t, 1, metrics.calls[1].Attempt)
+	assert.Error(t, metrics.calls[1].Err)
+
+	assert.Equal(t, http.StatusOK, metrics.calls[2].StatusCode)
+	assert.Equal(t, 0, metrics.calls[2].Code)
+	assert.Equal(t, 2, metrics.calls[2].Attempt)
+	assert.NoError(t, metrics.calls[2].Err)
+}
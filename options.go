@@ -0,0 +1,88 @@
+package seatalkbot
+
+import (
+	"log/slog"
+	"net/http"
+)
+
+// Option overrides a field of the Config passed to NewClient.
+type Option func(config *Config)
+
+// WithHTTPClient sets the *http.Client used for every HTTP calls.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(config *Config) {
+		config.HTTPClient = httpClient
+	}
+}
+
+// WithHost sets the url of the bot api.
+func WithHost(host string) Option {
+	return func(config *Config) {
+		config.Host = host
+	}
+}
+
+// WithLogger sets the logger of the client.
+func WithLogger(logger *slog.Logger) Option {
+	return func(config *Config) {
+		config.Logger = logger
+	}
+}
+
+// WithMetrics sets the Metrics receiving the measurements of the client.
+func WithMetrics(metrics Metrics) Option {
+	return func(config *Config) {
+		config.Metrics = metrics
+	}
+}
+
+// WithTracer sets the Tracer starting the spans of the client.
+func WithTracer(tracer Tracer) Option {
+	return func(config *Config) {
+		config.Tracer = tracer
+	}
+}
+
+// WithTracedRecipientHashKey pseudonymises the recipients in the span attributes with the key, see
+// Config.TracedRecipientHashKey.
+func WithTracedRecipientHashKey(key []byte) Option {
+	return func(config *Config) {
+		config.TracedRecipientHashKey = append([]byte(nil), key...)
+	}
+}
+
+// WithRetryPolicy retries the API calls failing with a transient error with the policy.
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(config *Config) {
+		config.RetryPolicy = &policy
+	}
+}
+
+// WithRateLimiter limits the rate of the API calls with the limiter, it can be shared by the clients of the same app.
+func WithRateLimiter(limiter *RateLimiter) Option {
+	return func(config *Config) {
+		config.RateLimiter = limiter
+	}
+}
+
+// WithoutRateLimiter stops the client from limiting the rate of the API calls, see Config.DisableRateLimiter.
+func WithoutRateLimiter() Option {
+	return func(config *Config) {
+		config.DisableRateLimiter = true
+	}
+}
+
+// WithoutAutoRefresh stops the client from refreshing the access token in the background, see
+// Config.DisableAutoRefresh.
+func WithoutAutoRefresh() Option {
+	return func(config *Config) {
+		config.DisableAutoRefresh = true
+	}
+}
+
+// WithLazyInit skips fetching the access token in NewClient, see Config.LazyInit.
+func WithLazyInit() Option {
+	return func(config *Config) {
+		config.LazyInit = true
+	}
+}
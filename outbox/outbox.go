@@ -0,0 +1,235 @@
+// Package outbox delivers the messages through a seatalkbot client with at-least-once delivery. The messages are
+// persisted in a Store before being sent, so they're not lost when seatalk is unavailable or the process restarts.
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/anandawira/seatalkbot"
+	"github.com/anandawira/seatalkbot/helper"
+)
+
+const (
+	defaultWorkers        = 1
+	defaultMaxAttempts    = 10
+	defaultInitialBackoff = 1 * time.Second
+	defaultMaxBackoff     = 5 * time.Minute
+	defaultPollInterval   = 1 * time.Second
+	defaultLease          = 1 * time.Minute
+	defaultRetention      = 7 * 24 * time.Hour
+
+	// pruneInterval is how often the delivered entries older than the retention are removed from the store.
+	pruneInterval = 1 * time.Hour
+)
+
+// Sender sends the messages, it's implemented by seatalkbot.Client.
+type Sender interface {
+	SendPrivateMessage(ctx context.Context, employeeCode string, message seatalkbot.Message) error
+	SendGroupMessage(ctx context.Context, groupID string, message seatalkbot.Message) (messageID string, err error)
+}
+
+type Config struct {
+	// Sender sends the messages, usually a seatalkbot.Client.
+	Sender Sender
+	// Store persists the messages until they're delivered.
+	Store Store
+	// Workers is the number of messages delivered at once. It's 1 by default.
+	Workers int
+	// MaxAttempts is the number of failed attempts after which a message is dead-lettered. It's 10 by default.
+	MaxAttempts int
+	// InitialBackoff is the wait before the first retry, it's doubled on every retry. It's 1 second by default.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the wait between the retries. It's 5 minutes by default.
+	MaxBackoff time.Duration
+	// PollInterval is how often the idle workers check the store for messages to deliver. It's 1 second by default.
+	PollInterval time.Duration
+	// Lease is how long a claimed message is hidden from the other workers. When a worker stops before finishing
+	// the delivery, the message is delivered again after the lease. It's 1 minute by default.
+	Lease time.Duration
+	// Retention is how long a delivered message is kept in the store. Enqueuing a message with the key of a delivered
+	// message is a no-op only while it's kept. It's 7 days by default.
+	Retention time.Duration
+}
+
+// Outbox persists the messages and delivers them in the background.
+type Outbox struct {
+	config Config
+}
+
+// New returns an Outbox with the config.
+func New(config Config) (*Outbox, error) {
+	if config.Sender == nil {
+		return nil, errors.New("sender should not be nil")
+	}
+	if config.Store == nil {
+		return nil, errors.New("store should not be nil")
+	}
+	if config.Workers <= 0 {
+		config.Workers = defaultWorkers
+	}
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = defaultMaxAttempts
+	}
+	if config.InitialBackoff <= 0 {
+		config.InitialBackoff = defaultInitialBackoff
+	}
+	if config.MaxBackoff <= 0 {
+		config.MaxBackoff = defaultMaxBackoff
+	}
+	if config.PollInterval <= 0 {
+		config.PollInterval = defaultPollInterval
+	}
+	if config.Lease <= 0 {
+		config.Lease = defaultLease
+	}
+	if config.Retention <= 0 {
+		config.Retention = defaultRetention
+	}
+
+	return &Outbox{config: config}, nil
+}
+
+// Enqueue persists the message to be delivered to the recipient. The key is the idempotency key of the message:
+// enqueuing a message with a key that has already been enqueued is a no-op, whatever the state of the first one is.
+func (o *Outbox) Enqueue(ctx context.Context, key string, recipient seatalkbot.Recipient, message seatalkbot.Message) error {
+	if key == "" {
+		return errors.New("key should not be empty")
+	}
+	if (recipient.EmployeeCode == "") == (recipient.GroupID == "") {
+		return errors.New("exactly one of EmployeeCode/GroupID must be set")
+	}
+
+	now := time.Now()
+
+	err := o.config.Store.Add(ctx, Entry{
+		ID:          key,
+		Recipient:   recipient,
+		Message:     message.Message(),
+		State:       StatePending,
+		NextAttempt: now,
+		CreatedAt:   now,
+	})
+	if errors.Is(err, ErrDuplicate) {
+		return nil
+	}
+
+	return err
+}
+
+// DeadLetters returns the messages that can't be delivered.
+func (o *Outbox) DeadLetters(ctx context.Context) ([]Entry, error) {
+	return o.config.Store.List(ctx, StateDead)
+}
+
+// Run delivers the messages and prunes the delivered ones older than the retention until ctx is done.
+// It always returns the context error.
+func (o *Outbox) Run(ctx context.Context) error {
+	var wg sync.WaitGroup
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		o.prune(ctx)
+	}()
+
+	for i := 0; i < o.config.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			o.work(ctx)
+		}()
+	}
+
+	wg.Wait()
+
+	return ctx.Err()
+}
+
+// work claims and delivers the messages one by one, and waits for PollInterval when there is nothing to deliver.
+func (o *Outbox) work(ctx context.Context) {
+	for ctx.Err() == nil {
+		entries, err := o.config.Store.Claim(ctx, time.Now(), 1, o.config.Lease)
+		if err != nil || len(entries) == 0 {
+			_ = helper.Sleep(ctx, o.config.PollInterval)
+			continue
+		}
+
+		for _, entry := range entries {
+			o.deliver(ctx, entry)
+		}
+	}
+}
+
+// prune removes the delivered messages older than the retention every pruneInterval.
+func (o *Outbox) prune(ctx context.Context) {
+	for ctx.Err() == nil {
+		// When the prune fails, it's retried on the next interval.
+		_ = o.config.Store.Prune(ctx, time.Now().Add(-o.config.Retention))
+		_ = helper.Sleep(ctx, pruneInterval)
+	}
+}
+
+// deliver sends the entry and updates it with the outcome. When the send is interrupted because ctx is done, the
+// entry stays claimed and is delivered again after the lease. A finished send is always recorded, even when ctx is
+// done in the meantime, so it's not sent twice.
+func (o *Outbox) deliver(ctx context.Context, entry Entry) {
+	messageID, err := o.send(ctx, entry)
+	if err != nil && ctx.Err() != nil {
+		return
+	}
+
+	switch {
+	case err == nil:
+		entry.State = StateDelivered
+		entry.MessageID = messageID
+		entry.LastError = ""
+		entry.DeliveredAt = time.Now()
+	default:
+		entry.Attempts++
+		entry.LastError = err.Error()
+
+		if permanent(err) || entry.Attempts >= o.config.MaxAttempts {
+			entry.State = StateDead
+		} else {
+			entry.NextAttempt = time.Now().Add(helper.Backoff(entry.Attempts, o.config.InitialBackoff, o.config.MaxBackoff))
+		}
+	}
+
+	// When the update fails, the entry is delivered again after the lease.
+	_ = o.config.Store.Update(context.WithoutCancel(ctx), entry)
+}
+
+func (o *Outbox) send(ctx context.Context, entry Entry) (messageID string, err error) {
+	message := rawMessage(entry.Message)
+
+	if entry.Recipient.EmployeeCode != "" {
+		return "", o.config.Sender.SendPrivateMessage(ctx, entry.Recipient.EmployeeCode, message)
+	}
+
+	return o.config.Sender.SendGroupMessage(ctx, entry.Recipient.GroupID, message)
+}
+
+// permanent reports whether the error won't go away by retrying, e.g. an invalid message or an unknown recipient.
+func permanent(err error) bool {
+	var apiErr *seatalkbot.APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	if seatalkbot.IsRateLimited(err) || seatalkbot.IsTokenExpired(err) {
+		return false
+	}
+
+	return apiErr.StatusCode == http.StatusOK || (apiErr.StatusCode >= 400 && apiErr.StatusCode < 500)
+}
+
+// rawMessage is a message restored from the store.
+type rawMessage json.RawMessage
+
+func (m rawMessage) Message() json.RawMessage {
+	return json.RawMessage(m)
+}
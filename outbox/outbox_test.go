@@ -0,0 +1,291 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anandawira/seatalkbot"
+)
+
+// fakeSender fails the first failures sends to every recipient with err, then succeeds.
+type fakeSender struct {
+	mu       sync.Mutex
+	failures int
+	err      error
+	attempts map[string]int
+	sent     []string
+}
+
+func (s *fakeSender) SendPrivateMessage(ctx context.Context, employeeCode string, message seatalkbot.Message) error {
+	_, err := s.SendGroupMessage(ctx, employeeCode, message)
+	return err
+}
+
+func (s *fakeSender) SendGroupMessage(_ context.Context, id string, _ seatalkbot.Message) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.attempts == nil {
+		s.attempts = make(map[string]int)
+	}
+
+	s.attempts[id]++
+	if s.attempts[id] <= s.failures {
+		return "", s.err
+	}
+
+	s.sent = append(s.sent, id)
+	return "m-" + id, nil
+}
+
+func (s *fakeSender) sentTo() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]string(nil), s.sent...)
+}
+
+func Test_Outbox_Run(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name          string
+		sender        *fakeSender
+		wantState     State
+		wantAttempts  int
+		wantMessageID string
+	}{
+		{
+			name:          "it should deliver the message",
+			sender:        &fakeSender{},
+			wantState:     StateDelivered,
+			wantMessageID: "m-g1",
+		},
+		{
+			name:          "it should retry the message when the error is transient",
+			sender:        &fakeSender{failures: 2, err: &seatalkbot.APIError{StatusCode: http.StatusServiceUnavailable}},
+			wantState:     StateDelivered,
+			wantAttempts:  2,
+			wantMessageID: "m-g1",
+		},
+		{
+			name:         "it should dead-letter the message when the error is permanent",
+			sender:       &fakeSender{failures: 1, err: &seatalkbot.APIError{StatusCode: http.StatusOK, Code: seatalkbot.CodeBotNotInGroup}},
+			wantState:    StateDead,
+			wantAttempts: 1,
+		},
+		{
+			name:         "it should dead-letter the message after max attempts",
+			sender:       &fakeSender{failures: 100, err: errors.New("connection reset")},
+			wantState:    StateDead,
+			wantAttempts: 3,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			store := NewMemoryStore()
+
+			o, err := New(Config{
+				Sender:         tt.sender,
+				Store:          store,
+				MaxAttempts:    3,
+				InitialBackoff: time.Millisecond,
+				MaxBackoff:     time.Millisecond,
+				PollInterval:   time.Millisecond,
+			})
+			require.NoError(t, err)
+
+			require.NoError(t, o.Enqueue(context.Background(), "key-1", seatalkbot.GroupRecipient("g1"), seatalkbot.TextMessage("abc", "")))
+
+			ctx, cancel := context.WithCancel(context.Background())
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				_ = o.Run(ctx)
+			}()
+
+			require.Eventually(t, func() bool {
+				entries, _ := store.List(context.Background(), tt.wantState)
+				return len(entries) == 1
+			}, time.Second, time.Millisecond)
+
+			cancel()
+			<-done
+
+			entries, err := store.List(context.Background(), tt.wantState)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantAttempts, entries[0].Attempts)
+			assert.Equal(t, tt.wantMessageID, entries[0].MessageID)
+		})
+	}
+}
+
+func Test_Outbox_Enqueue_idempotent(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "outbox.json")
+	sender := &fakeSender{}
+
+	store, err := OpenFileStore(path)
+	require.NoError(t, err)
+
+	o, err := New(Config{Sender: sender, Store: store, PollInterval: time.Millisecond})
+	require.NoError(t, err)
+
+	require.NoError(t, o.Enqueue(context.Background(), "key-1", seatalkbot.EmployeeRecipient("1"), seatalkbot.TextMessage("abc", "")))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = o.Run(ctx)
+	}()
+
+	require.Eventually(t, func() bool {
+		return len(sender.sentTo()) == 1
+	}, time.Second, time.Millisecond)
+
+	cancel()
+	<-done
+
+	// Simulate a restart: reopen the store and enqueue the same message again.
+	store, err = OpenFileStore(path)
+	require.NoError(t, err)
+
+	o, err = New(Config{Sender: sender, Store: store, PollInterval: time.Millisecond})
+	require.NoError(t, err)
+
+	require.NoError(t, o.Enqueue(context.Background(), "key-1", seatalkbot.EmployeeRecipient("1"), seatalkbot.TextMessage("abc", "")))
+
+	pending, err := store.List(context.Background(), StatePending)
+	require.NoError(t, err)
+	assert.Empty(t, pending)
+
+	delivered, err := store.List(context.Background(), StateDelivered)
+	require.NoError(t, err)
+	require.Len(t, delivered, 1)
+	assert.Equal(t, seatalkbot.EmployeeRecipient("1"), delivered[0].Recipient)
+	assert.JSONEq(t, `{"tag":"text","text":{"content":"abc"}}`, string(delivered[0].Message))
+}
+
+// blockingSender signals started and succeeds once ctx is done, like a send finishing during a shutdown.
+type blockingSender struct {
+	started chan struct{}
+}
+
+func (s *blockingSender) SendPrivateMessage(ctx context.Context, employeeCode string, message seatalkbot.Message) error {
+	_, err := s.SendGroupMessage(ctx, employeeCode, message)
+	return err
+}
+
+func (s *blockingSender) SendGroupMessage(ctx context.Context, id string, _ seatalkbot.Message) (string, error) {
+	close(s.started)
+	<-ctx.Done()
+	return "m-" + id, nil
+}
+
+func Test_Outbox_Run_cancelledDuringSend(t *testing.T) {
+	t.Parallel()
+	store := NewMemoryStore()
+	sender := &blockingSender{started: make(chan struct{})}
+
+	o, err := New(Config{Sender: sender, Store: store, PollInterval: time.Millisecond})
+	require.NoError(t, err)
+
+	require.NoError(t, o.Enqueue(context.Background(), "key-1", seatalkbot.GroupRecipient("g1"), seatalkbot.TextMessage("abc", "")))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = o.Run(ctx)
+	}()
+
+	<-sender.started
+	cancel()
+	<-done
+
+	delivered, err := store.List(context.Background(), StateDelivered)
+	require.NoError(t, err)
+	require.Len(t, delivered, 1)
+	assert.Equal(t, "m-g1", delivered[0].MessageID)
+}
+
+func Test_FileStore_Claim_nothingToClaim(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "outbox.json")
+
+	store, err := OpenFileStore(path)
+	require.NoError(t, err)
+
+	entries, err := store.Claim(context.Background(), time.Now(), 1, time.Minute)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+	assert.NoFileExists(t, path)
+}
+
+func Test_MemoryStore_Prune(t *testing.T) {
+	t.Parallel()
+	store := NewMemoryStore()
+	now := time.Now()
+
+	for _, entry := range []Entry{
+		{ID: "old", State: StateDelivered, DeliveredAt: now.Add(-2 * time.Hour)},
+		{ID: "new", State: StateDelivered, DeliveredAt: now},
+		{ID: "dead", State: StateDead, CreatedAt: now.Add(-2 * time.Hour)},
+	} {
+		require.NoError(t, store.Add(context.Background(), entry))
+	}
+
+	require.NoError(t, store.Prune(context.Background(), now.Add(-time.Hour)))
+
+	delivered, err := store.List(context.Background(), StateDelivered)
+	require.NoError(t, err)
+	require.Len(t, delivered, 1)
+	assert.Equal(t, "new", delivered[0].ID)
+
+	dead, err := store.List(context.Background(), StateDead)
+	require.NoError(t, err)
+	assert.Len(t, dead, 1)
+}
+
+func Test_Outbox_Enqueue_invalidRecipient(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name      string
+		recipient seatalkbot.Recipient
+	}{
+		{
+			name: "it should reject a recipient without employee code nor group id",
+		},
+		{
+			name:      "it should reject a recipient with both employee code and group id",
+			recipient: seatalkbot.Recipient{EmployeeCode: "1", GroupID: "g1"},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			store := NewMemoryStore()
+
+			o, err := New(Config{Sender: &fakeSender{}, Store: store})
+			require.NoError(t, err)
+
+			err = o.Enqueue(context.Background(), "key-1", tt.recipient, seatalkbot.TextMessage("abc", ""))
+
+			assert.EqualError(t, err, "exactly one of EmployeeCode/GroupID must be set")
+			pending, err := store.List(context.Background(), StatePending)
+			require.NoError(t, err)
+			assert.Empty(t, pending)
+		})
+	}
+}
@@ -0,0 +1,267 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/anandawira/seatalkbot"
+)
+
+// ErrDuplicate is returned by Store.Add when an entry with the same id already exists.
+var ErrDuplicate = errors.New("entry with the same id already exists")
+
+// State is the delivery state of an entry.
+type State string
+
+const (
+	// StatePending means the entry is waiting to be delivered.
+	StatePending State = "pending"
+	// StateDelivered means the entry has been delivered.
+	StateDelivered State = "delivered"
+	// StateDead means the entry can't be delivered, because of a permanent error or too many attempts.
+	StateDead State = "dead"
+)
+
+// Entry is a message to be delivered to a recipient.
+type Entry struct {
+	// ID is the idempotency key of the entry.
+	ID        string               `json:"id"`
+	Recipient seatalkbot.Recipient `json:"recipient"`
+	Message   json.RawMessage      `json:"message"`
+	State     State                `json:"state"`
+	// Attempts is the number of failed delivery attempts.
+	Attempts int `json:"attempts"`
+	// NextAttempt is when the entry can be claimed by a worker.
+	NextAttempt time.Time `json:"next_attempt"`
+	LastError   string    `json:"last_error,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	// MessageID is the id of the delivered message, it's only set when sending to a group.
+	MessageID string `json:"message_id,omitempty"`
+	// DeliveredAt is when the entry has been delivered.
+	DeliveredAt time.Time `json:"delivered_at,omitempty"`
+}
+
+// Store persists the entries. The implementation must be safe for concurrent use.
+type Store interface {
+	// Add stores a new entry. It returns ErrDuplicate when an entry with the same id already exists,
+	// whatever its state is.
+	Add(ctx context.Context, entry Entry) error
+	// Claim returns at most limit pending entries whose NextAttempt is not after now, oldest first, and postpones
+	// their NextAttempt to now + lease so they're not claimed by another worker in the meantime.
+	Claim(ctx context.Context, now time.Time, limit int, lease time.Duration) ([]Entry, error)
+	// Update replaces the entry with the same id.
+	Update(ctx context.Context, entry Entry) error
+	// List returns the entries in the state, oldest first.
+	List(ctx context.Context, state State) ([]Entry, error)
+	// Prune removes the delivered entries whose DeliveredAt is before the time.
+	Prune(ctx context.Context, before time.Time) error
+}
+
+// MemoryStore is a Store keeping the entries in memory, they're lost when the process exits.
+type MemoryStore struct {
+	mu      sync.Mutex
+	entries map[string]Entry
+
+	// persist is called with the entries after every change while holding the lock.
+	persist func(entries map[string]Entry) error
+}
+
+// NewMemoryStore returns an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{entries: make(map[string]Entry)}
+}
+
+// Add implements Store
+func (s *MemoryStore) Add(_ context.Context, entry Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.entries[entry.ID]; ok {
+		return ErrDuplicate
+	}
+
+	s.entries[entry.ID] = entry
+
+	return s.save(func() { delete(s.entries, entry.ID) })
+}
+
+// Claim implements Store
+func (s *MemoryStore) Claim(_ context.Context, now time.Time, limit int, lease time.Duration) ([]Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var claimed []Entry
+	for _, entry := range s.sorted(StatePending) {
+		if len(claimed) >= limit {
+			break
+		}
+		if entry.NextAttempt.After(now) {
+			continue
+		}
+
+		claimed = append(claimed, entry)
+	}
+
+	if len(claimed) == 0 {
+		return nil, nil
+	}
+
+	for _, entry := range claimed {
+		entry.NextAttempt = now.Add(lease)
+		s.entries[entry.ID] = entry
+	}
+
+	err := s.save(func() {
+		for _, entry := range claimed {
+			s.entries[entry.ID] = entry
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return claimed, nil
+}
+
+// Update implements Store
+func (s *MemoryStore) Update(_ context.Context, entry Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previous, ok := s.entries[entry.ID]
+	if !ok {
+		return errors.New("entry not found: " + entry.ID)
+	}
+
+	s.entries[entry.ID] = entry
+
+	return s.save(func() { s.entries[entry.ID] = previous })
+}
+
+// List implements Store
+func (s *MemoryStore) List(_ context.Context, state State) ([]Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.sorted(state), nil
+}
+
+// Prune implements Store
+func (s *MemoryStore) Prune(_ context.Context, before time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pruned := make(map[string]Entry)
+	for id, entry := range s.entries {
+		if entry.State == StateDelivered && entry.DeliveredAt.Before(before) {
+			pruned[id] = entry
+			delete(s.entries, id)
+		}
+	}
+
+	if len(pruned) == 0 {
+		return nil
+	}
+
+	return s.save(func() {
+		for id, entry := range pruned {
+			s.entries[id] = entry
+		}
+	})
+}
+
+// sorted returns the entries in the state, oldest first.
+func (s *MemoryStore) sorted(state State) []Entry {
+	var entries []Entry
+	for _, entry := range s.entries {
+		if entry.State == state {
+			entries = append(entries, entry)
+		}
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].CreatedAt.Equal(entries[j].CreatedAt) {
+			return entries[i].ID < entries[j].ID
+		}
+		return entries[i].CreatedAt.Before(entries[j].CreatedAt)
+	})
+
+	return entries
+}
+
+// save persists the entries, rollback reverts the change when it fails.
+func (s *MemoryStore) save(rollback func()) error {
+	if s.persist == nil {
+		return nil
+	}
+
+	if err := s.persist(s.entries); err != nil {
+		rollback()
+		return err
+	}
+
+	return nil
+}
+
+// FileStore is a Store keeping the entries in memory and persisting them as json in a local file after every change,
+// so they survive a restart. It must not be shared by several processes.
+type FileStore struct {
+	*MemoryStore
+}
+
+// OpenFileStore returns a FileStore with the entries in the file at path. The file is created on the first change.
+func OpenFileStore(path string) (*FileStore, error) {
+	s := NewMemoryStore()
+
+	b, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	if err == nil {
+		if err := json.Unmarshal(b, &s.entries); err != nil {
+			return nil, err
+		}
+	}
+
+	s.persist = func(entries map[string]Entry) error {
+		return writeFile(path, entries)
+	}
+
+	return &FileStore{MemoryStore: s}, nil
+}
+
+// writeFile writes the entries to the file at path. The file is replaced atomically, so it's never left partially
+// written.
+func writeFile(path string, entries map[string]Entry) error {
+	b, err := json.Marshal(entries)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(f.Name(), path)
+}
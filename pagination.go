@@ -0,0 +1,55 @@
+package seatalkbot
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+// pageFetcher fetches the page starting from the cursor, an empty cursor is the first page.
+// An empty nextCursor means there is no more page.
+type pageFetcher[T any] func(ctx context.Context, cursor string) (items []T, nextCursor string, err error)
+
+// paginate fetches the pages one by one and calls fn for every item. It stops when fn returns false,
+// when ctx is done or when fetch returns an error.
+func paginate[T any](ctx context.Context, fetch pageFetcher[T], fn func(item T) bool) error {
+	var cursor string
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		items, nextCursor, err := fetch(ctx, cursor)
+		if err != nil {
+			return err
+		}
+
+		for _, item := range items {
+			if !fn(item) {
+				return nil
+			}
+		}
+
+		if nextCursor == "" {
+			return nil
+		}
+		if nextCursor == cursor {
+			return fmt.Errorf("next cursor is the same as the current cursor: %s", cursor)
+		}
+
+		cursor = nextCursor
+	}
+}
+
+// paginationQuery returns the query of an API call that uses pagination, starting from the cursor.
+func paginationQuery(cursor string) url.Values {
+	q := url.Values{}
+	q.Set("page_size", strconv.Itoa(pageSize))
+	if cursor != "" {
+		q.Set("cursor", cursor)
+	}
+
+	return q
+}
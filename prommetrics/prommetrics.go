@@ -0,0 +1,215 @@
+// Package prommetrics exports the metrics of the seatalkbot client in the Prometheus text format, without depending
+// on the Prometheus client library.
+package prommetrics
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/anandawira/seatalkbot"
+)
+
+// DefaultBuckets are the upper bounds in seconds of the buckets of the duration histograms.
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+var _ seatalkbot.Metrics = (*Exporter)(nil)
+
+// Exporter implements seatalkbot.Metrics and serves the metrics over http in the Prometheus text format:
+//
+//   - seatalkbot_api_calls_total{endpoint, status_code, code}
+//   - seatalkbot_api_call_retries_total{endpoint}
+//   - seatalkbot_api_call_duration_seconds{endpoint}
+//   - seatalkbot_token_refreshes_total{result}
+//   - seatalkbot_token_refresh_duration_seconds
+//
+// It is safe to share an Exporter amongst many clients.
+type Exporter struct {
+	buckets []float64
+
+	mu                   sync.Mutex
+	apiCalls             map[apiCallKey]uint64
+	apiCallRetries       map[string]uint64
+	apiCallDurations     map[string]*histogram
+	tokenRefreshes       map[string]uint64
+	tokenRefreshDuration *histogram
+}
+
+type apiCallKey struct {
+	endpoint   string
+	statusCode int
+	code       int
+}
+
+// NewExporter returns an Exporter whose duration histograms have the buckets, DefaultBuckets when none is provided.
+func NewExporter(buckets ...float64) *Exporter {
+	if len(buckets) == 0 {
+		buckets = DefaultBuckets
+	}
+
+	buckets = append([]float64(nil), buckets...)
+	sort.Float64s(buckets)
+
+	return &Exporter{
+		buckets:              buckets,
+		apiCalls:             make(map[apiCallKey]uint64),
+		apiCallRetries:       make(map[string]uint64),
+		apiCallDurations:     make(map[string]*histogram),
+		tokenRefreshes:       make(map[string]uint64),
+		tokenRefreshDuration: newHistogram(buckets),
+	}
+}
+
+// ObserveAPICall implements seatalkbot.Metrics
+func (e *Exporter) ObserveAPICall(call seatalkbot.APICallMetric) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.apiCalls[apiCallKey{endpoint: call.Endpoint, statusCode: call.StatusCode, code: call.Code}]++
+
+	if call.Attempt > 1 {
+		e.apiCallRetries[call.Endpoint]++
+	}
+
+	h, ok := e.apiCallDurations[call.Endpoint]
+	if !ok {
+		h = newHistogram(e.buckets)
+		e.apiCallDurations[call.Endpoint] = h
+	}
+	h.observe(call.Duration.Seconds())
+}
+
+// ObserveTokenRefresh implements seatalkbot.Metrics
+func (e *Exporter) ObserveTokenRefresh(refresh seatalkbot.TokenRefreshMetric) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	result := "success"
+	if refresh.Err != nil {
+		result = "error"
+	}
+
+	e.tokenRefreshes[result]++
+	e.tokenRefreshDuration.observe(refresh.Duration.Seconds())
+}
+
+// ServeHTTP writes the metrics in the Prometheus text format.
+func (e *Exporter) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	var buf bytes.Buffer
+	e.write(&buf)
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_, _ = w.Write(buf.Bytes())
+}
+
+func (e *Exporter) write(buf *bytes.Buffer) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	writeHeader(buf, "seatalkbot_api_calls_total", "counter", "Number of HTTP calls to the bot api.")
+	keys := make([]apiCallKey, 0, len(e.apiCalls))
+	for key := range e.apiCalls {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := keys[i], keys[j]
+		if a.endpoint != b.endpoint {
+			return a.endpoint < b.endpoint
+		}
+		if a.statusCode != b.statusCode {
+			return a.statusCode < b.statusCode
+		}
+		return a.code < b.code
+	})
+	for _, key := range keys {
+		labels := formatLabels("endpoint", key.endpoint, "status_code", strconv.Itoa(key.statusCode), "code", strconv.Itoa(key.code))
+		fmt.Fprintf(buf, "seatalkbot_api_calls_total%s %d\n", labels, e.apiCalls[key])
+	}
+
+	writeHeader(buf, "seatalkbot_api_call_retries_total", "counter", "Number of HTTP calls to the bot api retrying a failed one.")
+	for _, endpoint := range sortedKeys(e.apiCallRetries) {
+		fmt.Fprintf(buf, "seatalkbot_api_call_retries_total%s %d\n", formatLabels("endpoint", endpoint), e.apiCallRetries[endpoint])
+	}
+
+	writeHeader(buf, "seatalkbot_api_call_duration_seconds", "histogram", "Duration of the HTTP calls to the bot api.")
+	for _, endpoint := range sortedKeys(e.apiCallDurations) {
+		e.apiCallDurations[endpoint].write(buf, "seatalkbot_api_call_duration_seconds", "endpoint", endpoint)
+	}
+
+	writeHeader(buf, "seatalkbot_token_refreshes_total", "counter", "Number of access token refreshes.")
+	for _, result := range sortedKeys(e.tokenRefreshes) {
+		fmt.Fprintf(buf, "seatalkbot_token_refreshes_total%s %d\n", formatLabels("result", result), e.tokenRefreshes[result])
+	}
+
+	writeHeader(buf, "seatalkbot_token_refresh_duration_seconds", "histogram", "Duration of the access token refreshes.")
+	e.tokenRefreshDuration.write(buf, "seatalkbot_token_refresh_duration_seconds")
+}
+
+// histogram counts the observations in cumulative buckets.
+type histogram struct {
+	buckets []float64
+	counts  []uint64
+	count   uint64
+	sum     float64
+}
+
+func newHistogram(buckets []float64) *histogram {
+	return &histogram{buckets: buckets, counts: make([]uint64, len(buckets))}
+}
+
+func (h *histogram) observe(v float64) {
+	for i, bound := range h.buckets {
+		if v <= bound {
+			h.counts[i]++
+		}
+	}
+
+	h.count++
+	h.sum += v
+}
+
+// write writes the buckets, sum and count of the histogram with the labels, a list of name and value pairs.
+func (h *histogram) write(buf *bytes.Buffer, name string, labels ...string) {
+	for i, bound := range h.buckets {
+		le := strconv.FormatFloat(bound, 'g', -1, 64)
+		fmt.Fprintf(buf, "%s_bucket%s %d\n", name, formatLabels(append(labels, "le", le)...), h.counts[i])
+	}
+	fmt.Fprintf(buf, "%s_bucket%s %d\n", name, formatLabels(append(labels, "le", "+Inf")...), h.count)
+	fmt.Fprintf(buf, "%s_sum%s %s\n", name, formatLabels(labels...), strconv.FormatFloat(h.sum, 'g', -1, 64))
+	fmt.Fprintf(buf, "%s_count%s %d\n", name, formatLabels(labels...), h.count)
+}
+
+func writeHeader(buf *bytes.Buffer, name, metricType, help string) {
+	fmt.Fprintf(buf, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, metricType)
+}
+
+// labelEscaper escapes the label values as required by the text format.
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// formatLabels formats the labels, a list of name and value pairs, e.g. {endpoint="/a",code="0"}.
+func formatLabels(labels ...string) string {
+	if len(labels) == 0 {
+		return ""
+	}
+
+	pairs := make([]string, 0, len(labels)/2)
+	for i := 0; i+1 < len(labels); i += 2 {
+		pairs = append(pairs, fmt.Sprintf(`%s="%s"`, labels[i], labelEscaper.Replace(labels[i+1])))
+	}
+
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,75 @@
+package prommetrics
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anandawira/seatalkbot"
+)
+
+func Test_Exporter_ServeHTTP(t *testing.T) {
+	t.Parallel()
+	e := NewExporter(0.1, 1)
+
+	e.ObserveAPICall(seatalkbot.APICallMetric{
+		Endpoint:   "/messaging/v2/group_chat",
+		StatusCode: http.StatusTooManyRequests,
+		Code:       seatalkbot.CodeRateLimited,
+		Duration:   50 * time.Millisecond,
+		Attempt:    1,
+		Err:        errors.New("rate limited"),
+	})
+	e.ObserveAPICall(seatalkbot.APICallMetric{
+		Endpoint:   "/messaging/v2/group_chat",
+		StatusCode: http.StatusOK,
+		Duration:   500 * time.Millisecond,
+		Attempt:    2,
+	})
+	e.ObserveTokenRefresh(seatalkbot.TokenRefreshMetric{Duration: 2 * time.Second})
+
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", http.NoBody))
+
+	body, err := io.ReadAll(w.Result().Body)
+	require.NoError(t, err)
+
+	assert.Equal(t, "text/plain; version=0.0.4; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Equal(t, `# HELP seatalkbot_api_calls_total Number of HTTP calls to the bot api.
+# TYPE seatalkbot_api_calls_total counter
+seatalkbot_api_calls_total{endpoint="/messaging/v2/group_chat",status_code="200",code="0"} 1
+seatalkbot_api_calls_total{endpoint="/messaging/v2/group_chat",status_code="429",code="101"} 1
+# HELP seatalkbot_api_call_retries_total Number of HTTP calls to the bot api retrying a failed one.
+# TYPE seatalkbot_api_call_retries_total counter
+seatalkbot_api_call_retries_total{endpoint="/messaging/v2/group_chat"} 1
+# HELP seatalkbot_api_call_duration_seconds Duration of the HTTP calls to the bot api.
+# TYPE seatalkbot_api_call_duration_seconds histogram
+seatalkbot_api_call_duration_seconds_bucket{endpoint="/messaging/v2/group_chat",le="0.1"} 1
+seatalkbot_api_call_duration_seconds_bucket{endpoint="/messaging/v2/group_chat",le="1"} 2
+seatalkbot_api_call_duration_seconds_bucket{endpoint="/messaging/v2/group_chat",le="+Inf"} 2
+seatalkbot_api_call_duration_seconds_sum{endpoint="/messaging/v2/group_chat"} 0.55
+seatalkbot_api_call_duration_seconds_count{endpoint="/messaging/v2/group_chat"} 2
+# HELP seatalkbot_token_refreshes_total Number of access token refreshes.
+# TYPE seatalkbot_token_refreshes_total counter
+seatalkbot_token_refreshes_total{result="success"} 1
+# HELP seatalkbot_token_refresh_duration_seconds Duration of the access token refreshes.
+# TYPE seatalkbot_token_refresh_duration_seconds histogram
+seatalkbot_token_refresh_duration_seconds_bucket{le="0.1"} 0
+seatalkbot_token_refresh_duration_seconds_bucket{le="1"} 0
+seatalkbot_token_refresh_duration_seconds_bucket{le="+Inf"} 1
+seatalkbot_token_refresh_duration_seconds_sum 2
+seatalkbot_token_refresh_duration_seconds_count 1
+`, string(body))
+}
+
+func Test_formatLabels(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, `{endpoint="a\"b\\c\n"}`, formatLabels("endpoint", "a\"b\\c\n"))
+	assert.Equal(t, "", formatLabels())
+}
@@ -0,0 +1,154 @@
+package seatalkbot
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/anandawira/seatalkbot/helper"
+)
+
+// ErrRateLimitExceeded is returned when the API call would have to wait for the rate limiter beyond
+// the deadline of the context.
+var ErrRateLimitExceeded = errors.New("rate limit would be exceeded before the context deadline")
+
+// RateLimit is the rate of a token bucket.
+type RateLimit struct {
+	// Rate is the number of API calls allowed per second.
+	Rate float64
+	// Burst is the maximum number of API calls allowed at once.
+	Burst int
+}
+
+type RateLimiterConfig struct {
+	// App limits all the API calls of the app.
+	App RateLimit
+	// Endpoints limits the API calls to each endpoint path, e.g. /messaging/v2/group_chat, on top of the App limit.
+	Endpoints map[string]RateLimit
+}
+
+// DefaultRateLimiterConfig returns conservative limits that keep the app below the seatalk quotas.
+func DefaultRateLimiterConfig() RateLimiterConfig {
+	return RateLimiterConfig{
+		App: RateLimit{Rate: 50, Burst: 50},
+		Endpoints: map[string]RateLimit{
+			"/messaging/v2/single_chat": {Rate: 10, Burst: 10},
+			"/messaging/v2/group_chat":  {Rate: 10, Burst: 10},
+		},
+	}
+}
+
+// RateLimiter limits the rate of the API calls with token buckets, per app and per endpoint. When the limit is
+// reached, the API call waits for its turn, or fails fast with ErrRateLimitExceeded when the context deadline
+// is too short to wait.
+// It is safe to share a RateLimiter amongst the clients of the same app.
+type RateLimiter struct {
+	app       *tokenBucket
+	endpoints map[string]*tokenBucket
+}
+
+// NewRateLimiter returns a RateLimiter with the limits in the config.
+func NewRateLimiter(config RateLimiterConfig) *RateLimiter {
+	l := &RateLimiter{
+		app:       newTokenBucket(config.App),
+		endpoints: make(map[string]*tokenBucket, len(config.Endpoints)),
+	}
+
+	for endpoint, limit := range config.Endpoints {
+		l.endpoints[endpoint] = newTokenBucket(limit)
+	}
+
+	return l
+}
+
+// Wait blocks until an API call to the endpoint is allowed. It returns ErrRateLimitExceeded without waiting when
+// the API call is not allowed before the deadline of ctx, and ctx.Err() when ctx is done while waiting.
+func (l *RateLimiter) Wait(ctx context.Context, endpoint string) error {
+	if l == nil {
+		return nil
+	}
+
+	buckets := []*tokenBucket{l.app}
+	if bucket, ok := l.endpoints[endpoint]; ok {
+		buckets = append(buckets, bucket)
+	}
+
+	now := time.Now()
+
+	var wait time.Duration
+	for _, bucket := range buckets {
+		wait = max(wait, bucket.reserve(now))
+	}
+
+	cancel := func() {
+		for _, bucket := range buckets {
+			bucket.cancel()
+		}
+	}
+
+	if deadline, ok := ctx.Deadline(); ok && now.Add(wait).After(deadline) {
+		cancel()
+		return ErrRateLimitExceeded
+	}
+
+	if err := helper.Sleep(ctx, wait); err != nil {
+		cancel()
+		return err
+	}
+
+	return nil
+}
+
+// tokenBucket allows the burst at once, then refills at the rate per second.
+// A bucket with a zero or negative rate allows everything.
+type tokenBucket struct {
+	limit RateLimit
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(limit RateLimit) *tokenBucket {
+	return &tokenBucket{
+		limit:  limit,
+		tokens: float64(limit.Burst),
+		last:   time.Now(),
+	}
+}
+
+// reserve takes a token and returns how long to wait until it's available. The tokens can go negative,
+// so the reservations are served in order.
+func (b *tokenBucket) reserve(now time.Time) time.Duration {
+	if b.limit.Rate <= 0 {
+		return 0
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if now.After(b.last) {
+		b.tokens = min(b.tokens+now.Sub(b.last).Seconds()*b.limit.Rate, float64(b.limit.Burst))
+		b.last = now
+	}
+
+	b.tokens--
+	if b.tokens >= 0 {
+		return 0
+	}
+
+	return time.Duration(-b.tokens / b.limit.Rate * float64(time.Second))
+}
+
+// cancel gives back the token of a reservation that is not used.
+func (b *tokenBucket) cancel() {
+	if b.limit.Rate <= 0 {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.tokens = min(b.tokens+1, float64(b.limit.Burst))
+}
@@ -0,0 +1,161 @@
+package seatalkbot
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_RateLimiter_Wait(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name       string
+		config     RateLimiterConfig
+		calls      int
+		endpoint   string
+		timeout    time.Duration
+		checkError require.ErrorAssertionFunc
+		minElapsed time.Duration
+	}{
+		{
+			name:       "it should not wait within the burst",
+			config:     RateLimiterConfig{App: RateLimit{Rate: 1, Burst: 5}},
+			calls:      5,
+			checkError: require.NoError,
+		},
+		{
+			name:       "it should wait for the token to be refilled after the burst",
+			config:     RateLimiterConfig{App: RateLimit{Rate: 20, Burst: 1}},
+			calls:      3,
+			checkError: require.NoError,
+			minElapsed: 90 * time.Millisecond,
+		},
+		{
+			name: "it should apply the endpoint limit on top of the app limit",
+			config: RateLimiterConfig{
+				App:       RateLimit{Rate: 100, Burst: 100},
+				Endpoints: map[string]RateLimit{"/messaging/v2/group_chat": {Rate: 1, Burst: 1}},
+			},
+			calls:      2,
+			endpoint:   "/messaging/v2/group_chat",
+			timeout:    100 * time.Millisecond,
+			checkError: require.Error,
+		},
+		{
+			name:       "it should fail fast when the deadline is too short to wait",
+			config:     RateLimiterConfig{App: RateLimit{Rate: 1, Burst: 1}},
+			calls:      2,
+			timeout:    100 * time.Millisecond,
+			checkError: require.Error,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			limiter := NewRateLimiter(tt.config)
+
+			ctx := context.Background()
+			if tt.timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tt.timeout)
+				defer cancel()
+			}
+
+			start := time.Now()
+
+			var err error
+			for i := 0; i < tt.calls && err == nil; i++ {
+				err = limiter.Wait(ctx, tt.endpoint)
+			}
+
+			tt.checkError(t, err)
+			if err != nil {
+				assert.ErrorIs(t, err, ErrRateLimitExceeded)
+				assert.Less(t, time.Since(start), tt.timeout, "it should fail without waiting")
+			}
+			assert.GreaterOrEqual(t, time.Since(start), tt.minElapsed)
+		})
+	}
+}
+
+func Test_RateLimiter_shared(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"code":0,"app_access_token":"abc"}`))
+	}))
+	defer server.Close()
+
+	limiter := NewRateLimiter(RateLimiterConfig{App: RateLimit{Rate: 1, Burst: 1}})
+
+	var clients []Client
+	for i := 0; i < 2; i++ {
+		c, err := NewClient(Config{
+			HTTPClient:  &http.Client{},
+			Host:        server.URL,
+			AppID:       "app-id",
+			AppSecret:   "app-secret",
+			RateLimiter: limiter,
+		})
+		require.NoError(t, err)
+		defer c.Close()
+
+		clients = append(clients, c)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	require.NoError(t, clients[0].SendPrivateMessage(ctx, "123", TextMessage("abc", "")))
+	assert.ErrorIs(t, clients[1].SendPrivateMessage(ctx, "123", TextMessage("abc", "")), ErrRateLimitExceeded)
+}
+
+func Test_NewClient_RateLimiter(t *testing.T) {
+	t.Parallel()
+	limiter := NewRateLimiter(DefaultRateLimiterConfig())
+	tests := []struct {
+		name        string
+		opts        []Option
+		wantLimiter func(t *testing.T, limiter *RateLimiter)
+	}{
+		{
+			name: "it should limit the rate with the default limits by default",
+			wantLimiter: func(t *testing.T, got *RateLimiter) {
+				require.NotNil(t, got)
+				assert.Equal(t, DefaultRateLimiterConfig().App, got.app.limit)
+				assert.Len(t, got.endpoints, len(DefaultRateLimiterConfig().Endpoints))
+			},
+		},
+		{
+			name: "it should use the rate limiter set by option",
+			opts: []Option{WithRateLimiter(limiter)},
+			wantLimiter: func(t *testing.T, got *RateLimiter) {
+				assert.Same(t, limiter, got)
+			},
+		},
+		{
+			name: "it should not limit the rate when the rate limiter is disabled",
+			opts: []Option{WithRateLimiter(limiter), WithoutRateLimiter()},
+			wantLimiter: func(t *testing.T, got *RateLimiter) {
+				assert.Nil(t, got)
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			c, err := NewClient(Config{TokenSource: StaticTokenSource("abc")}, tt.opts...)
+			require.NoError(t, err)
+			defer c.Close()
+
+			tt.wantLimiter(t, c.(*client).rateLimiter)
+		})
+	}
+}
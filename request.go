@@ -0,0 +1,262 @@
+package seatalkbot
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/anandawira/seatalkbot/helper"
+)
+
+// endpoint is an API of seatalk.
+type endpoint struct {
+	method string
+	path   string
+	// idempotent tells whether the API call can be retried safely when it's unknown whether seatalk has processed it.
+	idempotent bool
+	// public tells whether the API is called without the access token, i.e. the access token API itself.
+	// Its response body doesn't always have the code, so it only fails on a code other than 0. It's not limited by
+	// the rate limiter, so the access token can always be refreshed.
+	public bool
+}
+
+var (
+	endpointAccessToken          = endpoint{method: http.MethodPost, path: "/auth/app_access_token", idempotent: true, public: true}
+	endpointSingleChat           = endpoint{method: http.MethodPost, path: "/messaging/v2/single_chat"}
+	endpointGroupChat            = endpoint{method: http.MethodPost, path: "/messaging/v2/group_chat"}
+	endpointUpdateMessage        = endpoint{method: http.MethodPost, path: "/messaging/v2/update", idempotent: true}
+	endpointJoinedGroups         = endpoint{method: http.MethodGet, path: "/messaging/v2/group_chat/joined", idempotent: true}
+	endpointGroupInfo            = endpoint{method: http.MethodGet, path: "/messaging/v2/group_chat/info", idempotent: true}
+	endpointGroupMembers         = endpoint{method: http.MethodGet, path: "/messaging/v2/group_chat/members", idempotent: true}
+	endpointEmployeeCodeByEmail  = endpoint{method: http.MethodPost, path: "/contacts/v2/get_employee_code_with_email", idempotent: true}
+	endpointEmployeeCodeByMobile = endpoint{method: http.MethodPost, path: "/contacts/v2/get_employee_code_with_mobile", idempotent: true}
+)
+
+// do calls the endpoint with req and decodes the response body into Resp. req is sent as the query of a GET
+// request, so it must be url.Values, and as the json body otherwise.
+func do[Req, Resp any](ctx context.Context, c *client, e endpoint, req Req) (Resp, error) {
+	respBody, err := c.call(ctx, e, req)
+	if err != nil {
+		return *new(Resp), err
+	}
+
+	return helper.UnmarshalJSON[Resp](respBody)
+}
+
+// Call implements Client
+func (c *client) Call(ctx context.Context, method, path string, req, resp any) error {
+	if !strings.HasPrefix(path, "/") {
+		return fmt.Errorf("path should start with /, got: %q", path)
+	}
+
+	respBody, err := c.call(ctx, endpoint{method: method, path: path, idempotent: method == http.MethodGet}, req)
+	if err != nil {
+		return err
+	}
+
+	if resp == nil {
+		return nil
+	}
+
+	return json.Unmarshal(respBody, resp)
+}
+
+// call sends req to the endpoint, retrying it according to the retry policy. It returns the response body when
+// the code in it is 0, and an *APIError otherwise.
+func (c *client) call(ctx context.Context, e endpoint, req any) ([]byte, error) {
+	newRequest, err := c.requestBuilder(ctx, e, req)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		respBody []byte
+		attempt  int
+	)
+
+	err = c.retryPolicy.run(ctx, e.idempotent, func() error {
+		attempt++
+		if !e.public {
+			countAttempt(ctx)
+		}
+
+		var err error
+		respBody, err = c.doWithToken(ctx, e, attempt, newRequest)
+		return err
+	}, func(attempt int, wait time.Duration, err error) {
+		c.logger.InfoContext(ctx, "retrying api call", append([]any{
+			slog.String("endpoint", e.path),
+			slog.Int("attempt", attempt),
+			slog.Duration("wait", wait),
+			slog.Any("error", err),
+		}, recipientAttrs(ctx)...)...)
+	})
+
+	return respBody, err
+}
+
+// requestBuilder returns a function building a new request to the endpoint for every attempt.
+func (c *client) requestBuilder(ctx context.Context, e endpoint, req any) (func() (*http.Request, error), error) {
+	if e.method == http.MethodGet {
+		query, ok := req.(url.Values)
+		if !ok && req != nil {
+			return nil, fmt.Errorf("request of a GET api call should be url.Values, got: %T", req)
+		}
+
+		return func() (*http.Request, error) {
+			r, err := http.NewRequestWithContext(ctx, e.method, c.host+e.path, http.NoBody)
+			if err != nil {
+				return nil, err
+			}
+
+			r.URL.RawQuery = query.Encode()
+
+			return r, nil
+		}, nil
+	}
+
+	if req == nil {
+		return func() (*http.Request, error) {
+			return http.NewRequestWithContext(ctx, e.method, c.host+e.path, http.NoBody)
+		}, nil
+	}
+
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	return func() (*http.Request, error) {
+		r, err := http.NewRequestWithContext(ctx, e.method, c.host+e.path, bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+
+		r.Header.Set("Content-Type", "application/json")
+
+		return r, nil
+	}, nil
+}
+
+// doWithToken sends the request built by newRequest using the access token. When the access token is rejected by
+// seatalk, it's refreshed and the request is sent once more with the new access token.
+func (c *client) doWithToken(ctx context.Context, e endpoint, attempt int, newRequest func() (*http.Request, error)) ([]byte, error) {
+	if e.public {
+		return c.send(e, newRequest, "", attempt)
+	}
+
+	if c.tokens.expired() {
+		if err := c.tokens.refresh(ctx); err != nil {
+			return nil, fmt.Errorf("can't get access token, %w", err)
+		}
+	}
+
+	token := c.tokens.token()
+
+	respBody, err := c.send(e, newRequest, token, attempt)
+	if !IsTokenExpired(err) {
+		return respBody, err
+	}
+
+	c.logger.InfoContext(ctx, "access token rejected", slog.String("fingerprint", fingerprint(token)))
+
+	if err := c.tokens.refreshRejected(ctx, token); err != nil {
+		return nil, fmt.Errorf("can't refresh rejected access token, %w", err)
+	}
+
+	return c.send(e, newRequest, c.tokens.token(), attempt)
+}
+
+// send sends the request using the token, if any, attempt is the number of the attempt for the metrics.
+// It returns the response body when the code in it is 0, and an *APIError otherwise.
+func (c *client) send(e endpoint, newRequest func() (*http.Request, error), token string, attempt int) ([]byte, error) {
+	req, err := newRequest()
+	if err != nil {
+		return nil, err
+	}
+
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	ctx := req.Context()
+	attrs := append([]any{slog.String("endpoint", e.path)}, recipientAttrs(ctx)...)
+
+	if !e.public {
+		waitStart := time.Now()
+		if err := c.rateLimiter.Wait(ctx, e.path); err != nil {
+			c.logger.WarnContext(ctx, "api call not allowed by rate limiter", append(attrs, slog.Any("error", err))...)
+			return nil, err
+		}
+		if wait := time.Since(waitStart); wait >= time.Millisecond {
+			c.logger.DebugContext(ctx, "waited for rate limiter", append(attrs, slog.Duration("wait", wait))...)
+		}
+	}
+
+	start := time.Now()
+	statusCode, respBody, err := c.roundTrip(req, !e.public)
+	latency := time.Since(start)
+	attrs = append(attrs, slog.Duration("latency", latency))
+
+	metric := APICallMetric{
+		Endpoint:   e.path,
+		StatusCode: statusCode,
+		Duration:   latency,
+		Attempt:    attempt,
+		Err:        err,
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		metric.Code = apiErr.Code
+	}
+
+	c.metrics.ObserveAPICall(metric)
+
+	if err != nil {
+		if apiErr != nil {
+			attrs = append(attrs,
+				slog.Int("status_code", apiErr.StatusCode),
+				slog.Int("code", apiErr.Code),
+				slog.String("request_id", apiErr.RequestID),
+			)
+		}
+
+		c.logger.WarnContext(ctx, "api call failed", append(attrs, slog.Any("error", err))...)
+		return nil, err
+	}
+
+	c.logger.DebugContext(ctx, "api call succeeded", attrs...)
+
+	return respBody, nil
+}
+
+// roundTrip sends the request. It returns the status code and the response body when the code in it is 0, and an
+// *APIError otherwise. codeRequired tells whether a response body without the code is an error.
+func (c *client) roundTrip(req *http.Request, codeRequired bool) (statusCode int, respBody []byte, err error) {
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	defer resp.Body.Close()
+
+	respBody, err = io.ReadAll(resp.Body)
+	if err != nil {
+		return resp.StatusCode, nil, err
+	}
+
+	if apiErr := newAPIError(resp, respBody, codeRequired); apiErr != nil {
+		return resp.StatusCode, nil, apiErr
+	}
+
+	return resp.StatusCode, respBody, nil
+}
@@ -0,0 +1,122 @@
+package seatalkbot_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anandawira/seatalkbot"
+	"github.com/anandawira/seatalkbot/seatalkbottest"
+)
+
+func Test_client_Call(t *testing.T) {
+	t.Parallel()
+	type respBody struct {
+		Code  int `json:"code"`
+		Group struct {
+			GroupName string `json:"group_name"`
+		} `json:"group"`
+		MessageID string `json:"message_id"`
+	}
+
+	textMessage := map[string]any{"tag": "text", "text": map[string]any{"content": "abc"}}
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		req        any
+		checkError require.ErrorAssertionFunc
+		want       func(t *testing.T, resp respBody)
+	}{
+		{
+			name:       "it should send the request as the query of a GET api call",
+			method:     http.MethodGet,
+			path:       "/messaging/v2/group_chat/info",
+			req:        url.Values{"group_id": {"g1"}},
+			checkError: require.NoError,
+			want: func(t *testing.T, resp respBody) {
+				assert.Equal(t, "Group 1", resp.Group.GroupName)
+			},
+		},
+		{
+			name:       "it should send the request as the json body of a POST api call",
+			method:     http.MethodPost,
+			path:       "/messaging/v2/group_chat",
+			req:        map[string]any{"group_id": "g1", "message": textMessage},
+			checkError: require.NoError,
+			want: func(t *testing.T, resp respBody) {
+				assert.Equal(t, "message-1", resp.MessageID)
+			},
+		},
+		{
+			name:       "it should return error when the request of a GET api call is not url.Values",
+			method:     http.MethodGet,
+			path:       "/messaging/v2/group_chat/info",
+			req:        map[string]string{"group_id": "g1"},
+			checkError: require.Error,
+		},
+		{
+			name:       "it should return error when the path doesn't start with a slash",
+			method:     http.MethodPost,
+			path:       "messaging/v2/group_chat",
+			req:        map[string]any{"group_id": "g1", "message": textMessage},
+			checkError: require.Error,
+		},
+		{
+			name:   "it should return api error when response body code is not 0",
+			method: http.MethodPost,
+			path:   "/messaging/v2/group_chat",
+			req:    map[string]any{"group_id": "g2", "message": textMessage},
+			checkError: func(t require.TestingT, err error, _ ...interface{}) {
+				require.True(t, seatalkbot.IsBotNotInGroup(err))
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			s := seatalkbottest.NewServer()
+			defer s.Close()
+
+			s.AddGroup("g1", "Group 1")
+
+			c, err := seatalkbot.NewClient(seatalkbot.Config{Host: s.URL, AppID: "app-id", AppSecret: "app-secret"})
+			require.NoError(t, err)
+			defer c.Close()
+
+			var resp respBody
+			err = c.Call(context.Background(), tt.method, tt.path, tt.req, &resp)
+
+			tt.checkError(t, err)
+			if err == nil {
+				tt.want(t, resp)
+			}
+		})
+	}
+}
+
+func Test_client_Call_nilRequest(t *testing.T) {
+	t.Parallel()
+	var body []byte
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+		_, _ = w.Write([]byte(`{"code":0}`))
+	}))
+	defer server.Close()
+
+	c, err := seatalkbot.NewClient(seatalkbot.Config{Host: server.URL, TokenSource: seatalkbot.StaticTokenSource("abc")})
+	require.NoError(t, err)
+	defer c.Close()
+
+	require.NoError(t, c.Call(context.Background(), http.MethodPost, "/custom/api", nil, nil))
+	assert.Empty(t, body, "it should not send a body when the request is nil")
+}
@@ -0,0 +1,112 @@
+package seatalkbot
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/anandawira/seatalkbot/helper"
+)
+
+// RetryPolicy configures how the failed API calls are retried. Only the API calls failing with a transient error
+// are retried: rate limit, status code 5xx and network errors.
+//
+// Sending a message is not idempotent, so it's only retried when seatalk has surely not processed it, i.e. when
+// it's rejected by the rate limit or the connection can't be established. Set RetryAmbiguous to also retry it
+// on 5xx and network errors, at the risk of sending the message twice.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts, including the first one. 1 or lower disables the retry.
+	MaxAttempts int
+	// InitialBackoff is the base wait before the first retry. It's doubled on every retry, with jitter.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the wait between the attempts, the wait is not capped when it's zero. The Retry-After header
+	// returned by seatalk is respected even when it's longer.
+	MaxBackoff time.Duration
+	// RetryAmbiguous allows retrying the non-idempotent API calls when it's unknown whether seatalk has processed
+	// them. It might send the same message twice.
+	RetryAmbiguous bool
+}
+
+// DefaultRetryPolicy returns the recommended RetryPolicy: 3 attempts with a backoff between 500ms and 5s.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: 500 * time.Millisecond,
+		MaxBackoff:     5 * time.Second,
+	}
+}
+
+// run calls fn until it succeeds, it fails with an error that can't be retried or MaxAttempts is reached.
+// onRetry is called before waiting for the next attempt.
+func (p RetryPolicy) run(ctx context.Context, idempotent bool, fn func() error, onRetry func(attempt int, wait time.Duration, err error)) error {
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil || attempt >= p.MaxAttempts || !p.retryable(ctx, err, idempotent) {
+			return err
+		}
+
+		wait := helper.Backoff(attempt, p.InitialBackoff, p.MaxBackoff)
+
+		var apiErr *APIError
+		if errors.As(err, &apiErr) && apiErr.retryAfter > 0 {
+			wait = apiErr.retryAfter
+		}
+
+		onRetry(attempt, wait, err)
+
+		if err := helper.Sleep(ctx, wait); err != nil {
+			return err
+		}
+	}
+}
+
+// retryable reports whether the API call made with ctx and failing with err can be retried. It's not retried when
+// ctx is done, but a timeout of the *http.Client is retried like the other network errors.
+func (p RetryPolicy) retryable(ctx context.Context, err error, idempotent bool) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+
+	if IsRateLimited(err) {
+		return true
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode >= http.StatusInternalServerError && (idempotent || p.RetryAmbiguous)
+	}
+
+	var urlErr *url.Error
+	if !errors.As(err, &urlErr) {
+		return false
+	}
+
+	// The request is not sent when the connection can't be established.
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		return true
+	}
+
+	return idempotent || p.RetryAmbiguous
+}
+
+// parseRetryAfter returns the duration of the Retry-After header, which is either in seconds or a http date.
+func parseRetryAfter(header string) time.Duration {
+	if header == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(header); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+
+	if date, err := http.ParseTime(header); err == nil {
+		return max(time.Until(date), 0)
+	}
+
+	return 0
+}
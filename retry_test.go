@@ -0,0 +1,238 @@
+package seatalkbot
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_client_retry(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name         string
+		retryAmbig   bool
+		call         func(c Client) error
+		respond      func(w http.ResponseWriter)
+		checkError   require.ErrorAssertionFunc
+		wantAttempts int32
+	}{
+		{
+			name: "it should retry idempotent api call on status code 5xx",
+			call: func(c Client) error {
+				_, err := c.GetGroupIDs(context.Background())
+				return err
+			},
+			respond: func(w http.ResponseWriter) {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			},
+			checkError:   require.Error,
+			wantAttempts: 3,
+		},
+		{
+			name: "it should not retry sending a message on status code 5xx",
+			call: func(c Client) error {
+				return c.SendPrivateMessage(context.Background(), "123", TextMessage("abc", ""))
+			},
+			respond: func(w http.ResponseWriter) {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			},
+			checkError:   require.Error,
+			wantAttempts: 1,
+		},
+		{
+			name:       "it should retry sending a message on status code 5xx when ambiguous retry is allowed",
+			retryAmbig: true,
+			call: func(c Client) error {
+				return c.SendPrivateMessage(context.Background(), "123", TextMessage("abc", ""))
+			},
+			respond: func(w http.ResponseWriter) {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			},
+			checkError:   require.Error,
+			wantAttempts: 3,
+		},
+		{
+			name: "it should retry sending a message when rate limited",
+			call: func(c Client) error {
+				return c.SendPrivateMessage(context.Background(), "123", TextMessage("abc", ""))
+			},
+			respond: func(w http.ResponseWriter) {
+				w.Header().Set("Retry-After", "0")
+				w.WriteHeader(http.StatusOK)
+				_, _ = w.Write([]byte(`{"code":101}`))
+			},
+			checkError:   require.Error,
+			wantAttempts: 3,
+		},
+		{
+			name: "it should not retry when the request is invalid",
+			call: func(c Client) error {
+				_, err := c.GetGroupIDs(context.Background())
+				return err
+			},
+			respond: func(w http.ResponseWriter) {
+				w.WriteHeader(http.StatusOK)
+				_, _ = w.Write([]byte(`{"code":102}`))
+			},
+			checkError:   require.Error,
+			wantAttempts: 1,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var attempts atomic.Int32
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/auth/app_access_token":
+					w.WriteHeader(http.StatusOK)
+					_, _ = w.Write([]byte(`{"app_access_token":"abc"}`))
+
+				default:
+					attempts.Add(1)
+					tt.respond(w)
+				}
+			}))
+			defer server.Close()
+
+			c, err := NewClient(Config{
+				HTTPClient: &http.Client{},
+				Host:       server.URL,
+				AppID:      "app-id",
+				AppSecret:  "app-secret",
+				RetryPolicy: &RetryPolicy{
+					MaxAttempts:    3,
+					InitialBackoff: time.Millisecond,
+					MaxBackoff:     time.Millisecond,
+					RetryAmbiguous: tt.retryAmbig,
+				},
+			})
+			require.NoError(t, err)
+
+			err = tt.call(c)
+
+			tt.checkError(t, err)
+			assert.Equal(t, tt.wantAttempts, attempts.Load())
+		})
+	}
+}
+
+func Test_client_retry_success(t *testing.T) {
+	t.Parallel()
+	var attempts atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/auth/app_access_token":
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write([]byte(`{"app_access_token":"abc"}`))
+
+		default:
+			if attempts.Add(1) == 1 {
+				w.WriteHeader(http.StatusBadGateway)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write([]byte(`{"code":0,"next_cursor":"","joined_group_chats":{"group_id":["abc"]}}`))
+		}
+	}))
+	defer server.Close()
+
+	policy := DefaultRetryPolicy()
+	policy.InitialBackoff = time.Millisecond
+
+	c, err := NewClient(Config{
+		HTTPClient:  &http.Client{},
+		Host:        server.URL,
+		AppID:       "app-id",
+		AppSecret:   "app-secret",
+		RetryPolicy: &policy,
+	})
+	require.NoError(t, err)
+
+	groupIDs, err := c.GetGroupIDs(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"abc"}, groupIDs)
+	assert.Equal(t, int32(2), attempts.Load())
+}
+
+func Test_client_retry_httpClientTimeout(t *testing.T) {
+	t.Parallel()
+	var attempts atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/auth/app_access_token":
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write([]byte(`{"app_access_token":"abc"}`))
+
+		default:
+			if attempts.Add(1) == 1 {
+				time.Sleep(200 * time.Millisecond)
+			}
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write([]byte(`{"code":0,"next_cursor":"","joined_group_chats":{"group_id":["abc"]}}`))
+		}
+	}))
+	defer server.Close()
+
+	policy := DefaultRetryPolicy()
+	policy.InitialBackoff = time.Millisecond
+
+	c, err := NewClient(Config{
+		HTTPClient:  &http.Client{Timeout: 50 * time.Millisecond},
+		Host:        server.URL,
+		AppID:       "app-id",
+		AppSecret:   "app-secret",
+		RetryPolicy: &policy,
+	})
+	require.NoError(t, err)
+	defer c.Close()
+
+	groupIDs, err := c.GetGroupIDs(context.Background())
+
+	require.NoError(t, err, "it should retry the api call timed out by the http client")
+	assert.Equal(t, []string{"abc"}, groupIDs)
+	assert.Equal(t, int32(2), attempts.Load())
+}
+
+func Test_client_retry_uncappedBackoff(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/auth/app_access_token":
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write([]byte(`{"app_access_token":"abc"}`))
+
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	c, err := NewClient(Config{
+		HTTPClient:  &http.Client{},
+		Host:        server.URL,
+		AppID:       "app-id",
+		AppSecret:   "app-secret",
+		RetryPolicy: &RetryPolicy{MaxAttempts: 3, InitialBackoff: 20 * time.Millisecond},
+	})
+	require.NoError(t, err)
+	defer c.Close()
+
+	start := time.Now()
+	_, err = c.GetGroupIDs(context.Background())
+
+	require.Error(t, err)
+	// The waits are at least half of 20ms and 40ms with the jitter.
+	assert.GreaterOrEqual(t, time.Since(start), 30*time.Millisecond, "it should back off when MaxBackoff is not set")
+}
@@ -0,0 +1,158 @@
+package seatalkbottest
+
+import (
+	"context"
+
+	"github.com/anandawira/seatalkbot"
+)
+
+var _ seatalkbot.Client = (*MockClient)(nil)
+
+// MockClient implements seatalkbot.Client by calling the function of each method.
+// A method whose function is nil returns the zero values.
+type MockClient struct {
+	SendPrivateMessageFunc       func(ctx context.Context, employeeCode string, message seatalkbot.Message) error
+	GetGroupIDsFunc              func(ctx context.Context) ([]string, error)
+	IterateGroupIDsFunc          func(ctx context.Context, fn func(groupID string) bool) error
+	GetGroupInfoFunc             func(ctx context.Context, groupID string) (seatalkbot.GroupInfo, error)
+	ListGroupMembersFunc         func(ctx context.Context, groupID string) ([]seatalkbot.Employee, error)
+	SendGroupMessageFunc         func(ctx context.Context, groupID string, message seatalkbot.Message) (string, error)
+	ReplyInThreadFunc            func(ctx context.Context, groupID, threadID string, message seatalkbot.Message) (string, error)
+	BroadcastFunc                func(ctx context.Context, recipients []seatalkbot.Recipient, message seatalkbot.Message, opts seatalkbot.BroadcastOptions) (seatalkbot.BroadcastReport, error)
+	UpdateInteractiveMessageFunc func(ctx context.Context, messageID string, message seatalkbot.Message) error
+	GetEmployeeCodesByEmailFunc  func(ctx context.Context, emails []string) ([]seatalkbot.EmployeeLookup, error)
+	GetEmployeeCodesByMobileFunc func(ctx context.Context, mobiles []string) ([]seatalkbot.EmployeeLookup, error)
+	UpdateAccessTokenFunc        func(ctx context.Context) error
+	AccessTokenFunc              func() string
+	HealthFunc                   func() seatalkbot.Health
+	CallFunc                     func(ctx context.Context, method, path string, req, resp any) error
+	CloseFunc                    func() error
+}
+
+func (m *MockClient) SendPrivateMessage(ctx context.Context, employeeCode string, message seatalkbot.Message) error {
+	if m.SendPrivateMessageFunc == nil {
+		return nil
+	}
+
+	return m.SendPrivateMessageFunc(ctx, employeeCode, message)
+}
+
+func (m *MockClient) GetGroupIDs(ctx context.Context) ([]string, error) {
+	if m.GetGroupIDsFunc == nil {
+		return nil, nil
+	}
+
+	return m.GetGroupIDsFunc(ctx)
+}
+
+func (m *MockClient) IterateGroupIDs(ctx context.Context, fn func(groupID string) bool) error {
+	if m.IterateGroupIDsFunc == nil {
+		return nil
+	}
+
+	return m.IterateGroupIDsFunc(ctx, fn)
+}
+
+func (m *MockClient) GetGroupInfo(ctx context.Context, groupID string) (seatalkbot.GroupInfo, error) {
+	if m.GetGroupInfoFunc == nil {
+		return seatalkbot.GroupInfo{}, nil
+	}
+
+	return m.GetGroupInfoFunc(ctx, groupID)
+}
+
+func (m *MockClient) ListGroupMembers(ctx context.Context, groupID string) ([]seatalkbot.Employee, error) {
+	if m.ListGroupMembersFunc == nil {
+		return nil, nil
+	}
+
+	return m.ListGroupMembersFunc(ctx, groupID)
+}
+
+func (m *MockClient) SendGroupMessage(ctx context.Context, groupID string, message seatalkbot.Message) (string, error) {
+	if m.SendGroupMessageFunc == nil {
+		return "", nil
+	}
+
+	return m.SendGroupMessageFunc(ctx, groupID, message)
+}
+
+func (m *MockClient) ReplyInThread(ctx context.Context, groupID, threadID string, message seatalkbot.Message) (string, error) {
+	if m.ReplyInThreadFunc == nil {
+		return "", nil
+	}
+
+	return m.ReplyInThreadFunc(ctx, groupID, threadID, message)
+}
+
+func (m *MockClient) Broadcast(ctx context.Context, recipients []seatalkbot.Recipient, message seatalkbot.Message, opts seatalkbot.BroadcastOptions) (seatalkbot.BroadcastReport, error) {
+	if m.BroadcastFunc == nil {
+		return seatalkbot.BroadcastReport{}, nil
+	}
+
+	return m.BroadcastFunc(ctx, recipients, message, opts)
+}
+
+func (m *MockClient) UpdateInteractiveMessage(ctx context.Context, messageID string, message seatalkbot.Message) error {
+	if m.UpdateInteractiveMessageFunc == nil {
+		return nil
+	}
+
+	return m.UpdateInteractiveMessageFunc(ctx, messageID, message)
+}
+
+func (m *MockClient) GetEmployeeCodesByEmail(ctx context.Context, emails []string) ([]seatalkbot.EmployeeLookup, error) {
+	if m.GetEmployeeCodesByEmailFunc == nil {
+		return nil, nil
+	}
+
+	return m.GetEmployeeCodesByEmailFunc(ctx, emails)
+}
+
+func (m *MockClient) GetEmployeeCodesByMobile(ctx context.Context, mobiles []string) ([]seatalkbot.EmployeeLookup, error) {
+	if m.GetEmployeeCodesByMobileFunc == nil {
+		return nil, nil
+	}
+
+	return m.GetEmployeeCodesByMobileFunc(ctx, mobiles)
+}
+
+func (m *MockClient) UpdateAccessToken(ctx context.Context) error {
+	if m.UpdateAccessTokenFunc == nil {
+		return nil
+	}
+
+	return m.UpdateAccessTokenFunc(ctx)
+}
+
+func (m *MockClient) AccessToken() string {
+	if m.AccessTokenFunc == nil {
+		return ""
+	}
+
+	return m.AccessTokenFunc()
+}
+
+func (m *MockClient) Health() seatalkbot.Health {
+	if m.HealthFunc == nil {
+		return seatalkbot.Health{}
+	}
+
+	return m.HealthFunc()
+}
+
+func (m *MockClient) Call(ctx context.Context, method, path string, req, resp any) error {
+	if m.CallFunc == nil {
+		return nil
+	}
+
+	return m.CallFunc(ctx, method, path, req, resp)
+}
+
+func (m *MockClient) Close() error {
+	if m.CloseFunc == nil {
+		return nil
+	}
+
+	return m.CloseFunc()
+}
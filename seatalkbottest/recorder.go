@@ -0,0 +1,282 @@
+package seatalkbottest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/tidwall/gjson"
+
+	"github.com/anandawira/seatalkbot"
+)
+
+var _ seatalkbot.Client = (*RecordingClient)(nil)
+
+// Response is a scripted response of a RecordingClient method. Only the fields returned by the method are used,
+// e.g. MessageID and Err for SendGroupMessage.
+type Response struct {
+	// MessageID is the id of the sent message. An id is generated when it's empty.
+	MessageID       string
+	GroupIDs        []string
+	GroupInfo       seatalkbot.GroupInfo
+	Members         []seatalkbot.Employee
+	EmployeeLookups []seatalkbot.EmployeeLookup
+	// Body is the response body decoded by Call.
+	Body json.RawMessage
+	Err  error
+}
+
+// RecordingClient implements seatalkbot.Client without calling seatalk. It records every message sent, decoded,
+// and returns the scripted responses of each method.
+// It is safe to use it from multiple goroutines.
+type RecordingClient struct {
+	mu        sync.Mutex
+	messages  []ReceivedMessage
+	responses map[string][]Response
+	calls     map[string]int
+	health    seatalkbot.Health
+}
+
+// NewRecordingClient returns a RecordingClient whose methods succeed until scripted otherwise.
+func NewRecordingClient() *RecordingClient {
+	now := time.Now()
+
+	return &RecordingClient{
+		responses: make(map[string][]Response),
+		calls:     make(map[string]int),
+		health:    seatalkbot.Health{LastRefresh: now, Expiry: now.Add(tokenLifetime)},
+	}
+}
+
+// Script queues the responses of the method, named after the seatalkbot.Client method, e.g. "SendGroupMessage".
+// The responses are returned in order, one per call. Once they're used up, the method succeeds with the zero
+// values and a generated message id.
+// The messages sent with an error response are not recorded.
+func (c *RecordingClient) Script(method string, responses ...Response) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.responses[method] = append(c.responses[method], responses...)
+}
+
+// Calls returns the number of calls of the method, including the failed ones.
+func (c *RecordingClient) Calls(method string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.calls[method]
+}
+
+// Messages returns the messages sent so far, in the order they're sent.
+func (c *RecordingClient) Messages() []ReceivedMessage {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return append([]ReceivedMessage(nil), c.messages...)
+}
+
+// AssertEmployeeReceivedText asserts that a text message with the content is sent to the employee.
+func (c *RecordingClient) AssertEmployeeReceivedText(t testing.TB, employeeCode, text string) bool {
+	t.Helper()
+
+	return assertEmployeeReceivedText(t, c.Messages(), employeeCode, text)
+}
+
+// AssertGroupReceivedText asserts that a text message with the content is sent to the group.
+func (c *RecordingClient) AssertGroupReceivedText(t testing.TB, groupID, text string) bool {
+	t.Helper()
+
+	return assertGroupReceivedText(t, c.Messages(), groupID, text)
+}
+
+func (c *RecordingClient) SendPrivateMessage(_ context.Context, employeeCode string, message seatalkbot.Message) error {
+	_, err := c.send("SendPrivateMessage", ReceivedMessage{EmployeeCode: employeeCode}, message)
+	return err
+}
+
+func (c *RecordingClient) GetGroupIDs(_ context.Context) ([]string, error) {
+	r := c.next("GetGroupIDs")
+	return r.GroupIDs, r.Err
+}
+
+// IterateGroupIDs calls fn for the group ids of the response scripted for "IterateGroupIDs".
+func (c *RecordingClient) IterateGroupIDs(ctx context.Context, fn func(groupID string) bool) error {
+	r := c.next("IterateGroupIDs")
+	if r.Err != nil {
+		return r.Err
+	}
+
+	for _, groupID := range r.GroupIDs {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if !fn(groupID) {
+			return nil
+		}
+	}
+
+	return nil
+}
+
+func (c *RecordingClient) GetGroupInfo(_ context.Context, _ string) (seatalkbot.GroupInfo, error) {
+	r := c.next("GetGroupInfo")
+	return r.GroupInfo, r.Err
+}
+
+func (c *RecordingClient) ListGroupMembers(_ context.Context, _ string) ([]seatalkbot.Employee, error) {
+	r := c.next("ListGroupMembers")
+	return r.Members, r.Err
+}
+
+func (c *RecordingClient) SendGroupMessage(_ context.Context, groupID string, message seatalkbot.Message) (string, error) {
+	return c.send("SendGroupMessage", ReceivedMessage{GroupID: groupID}, message)
+}
+
+func (c *RecordingClient) ReplyInThread(_ context.Context, groupID, threadID string, message seatalkbot.Message) (string, error) {
+	return c.send("ReplyInThread", ReceivedMessage{GroupID: groupID, ThreadID: threadID}, message)
+}
+
+// Broadcast sends the message to the recipients one by one with SendPrivateMessage or SendGroupMessage, so their
+// scripted responses apply to each recipient. A response scripted for "Broadcast" with an error fails the whole
+// broadcast instead.
+func (c *RecordingClient) Broadcast(ctx context.Context, recipients []seatalkbot.Recipient, message seatalkbot.Message, _ seatalkbot.BroadcastOptions) (seatalkbot.BroadcastReport, error) {
+	if r := c.next("Broadcast"); r.Err != nil {
+		return seatalkbot.BroadcastReport{}, r.Err
+	}
+
+	report := seatalkbot.BroadcastReport{Results: make([]seatalkbot.BroadcastResult, len(recipients))}
+
+	for i, recipient := range recipients {
+		result := &report.Results[i]
+		result.Recipient = recipient
+
+		if err := ctx.Err(); err != nil {
+			result.Err = err
+			continue
+		}
+
+		result.Attempts = 1
+		if recipient.GroupID != "" {
+			result.MessageID, result.Err = c.send("SendGroupMessage", ReceivedMessage{GroupID: recipient.GroupID}, message)
+		} else {
+			result.MessageID, result.Err = c.send("SendPrivateMessage", ReceivedMessage{EmployeeCode: recipient.EmployeeCode}, message)
+		}
+	}
+
+	return report, ctx.Err()
+}
+
+// UpdateInteractiveMessage replaces the recorded message with the messageID, if any.
+func (c *RecordingClient) UpdateInteractiveMessage(_ context.Context, messageID string, message seatalkbot.Message) error {
+	if r := c.next("UpdateInteractiveMessage"); r.Err != nil {
+		return r.Err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for i, m := range c.messages {
+		if m.MessageID == messageID {
+			c.messages[i] = decodeMessage(m, message)
+		}
+	}
+
+	return nil
+}
+
+func (c *RecordingClient) GetEmployeeCodesByEmail(_ context.Context, _ []string) ([]seatalkbot.EmployeeLookup, error) {
+	r := c.next("GetEmployeeCodesByEmail")
+	return r.EmployeeLookups, r.Err
+}
+
+func (c *RecordingClient) GetEmployeeCodesByMobile(_ context.Context, _ []string) ([]seatalkbot.EmployeeLookup, error) {
+	r := c.next("GetEmployeeCodesByMobile")
+	return r.EmployeeLookups, r.Err
+}
+
+func (c *RecordingClient) UpdateAccessToken(_ context.Context) error {
+	return c.next("UpdateAccessToken").Err
+}
+
+func (c *RecordingClient) AccessToken() string {
+	return "recording-client-token"
+}
+
+// Call decodes the Body of the response scripted for "Call" into resp.
+func (c *RecordingClient) Call(_ context.Context, _, _ string, _, resp any) error {
+	r := c.next("Call")
+	if r.Err != nil || resp == nil || r.Body == nil {
+		return r.Err
+	}
+
+	return json.Unmarshal(r.Body, resp)
+}
+
+// SetHealth sets the Health returned by the client, it's healthy by default.
+func (c *RecordingClient) SetHealth(health seatalkbot.Health) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.health = health
+}
+
+func (c *RecordingClient) Health() seatalkbot.Health {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.health
+}
+
+func (c *RecordingClient) Close() error {
+	return nil
+}
+
+// send records the message to the recipient m unless the scripted response of the method is an error.
+func (c *RecordingClient) send(method string, m ReceivedMessage, message seatalkbot.Message) (string, error) {
+	r := c.next(method)
+	if r.Err != nil {
+		return "", r.Err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	m.MessageID = r.MessageID
+	if m.MessageID == "" {
+		m.MessageID = fmt.Sprintf("message-%d", len(c.messages)+1)
+	}
+
+	c.messages = append(c.messages, decodeMessage(m, message))
+
+	return m.MessageID, nil
+}
+
+// next counts the call of the method and returns its next scripted response.
+func (c *RecordingClient) next(method string) Response {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.calls[method]++
+
+	responses := c.responses[method]
+	if len(responses) == 0 {
+		return Response{}
+	}
+
+	c.responses[method] = responses[1:]
+	return responses[0]
+}
+
+// decodeMessage sets the content of the message in m.
+func decodeMessage(m ReceivedMessage, message seatalkbot.Message) ReceivedMessage {
+	raw := message.Message()
+
+	m.Tag = gjson.GetBytes(raw, "tag").String()
+	m.Text = gjson.GetBytes(raw, "text.content").String()
+	m.Raw = json.RawMessage(raw)
+
+	return m
+}
@@ -0,0 +1,130 @@
+package seatalkbottest
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anandawira/seatalkbot"
+)
+
+func Test_RecordingClient_send(t *testing.T) {
+	t.Parallel()
+	errSend := errors.New("send failed")
+
+	tests := []struct {
+		name         string
+		script       []Response
+		checkError   require.ErrorAssertionFunc
+		wantID       string
+		wantMessages int
+	}{
+		{
+			name:         "it should record the message and generate its id",
+			checkError:   require.NoError,
+			wantID:       "message-1",
+			wantMessages: 1,
+		},
+		{
+			name:         "it should return the scripted message id",
+			script:       []Response{{MessageID: "abc"}},
+			checkError:   require.NoError,
+			wantID:       "abc",
+			wantMessages: 1,
+		},
+		{
+			name:         "it should return the scripted error without recording the message",
+			script:       []Response{{Err: errSend}},
+			checkError:   require.Error,
+			wantMessages: 0,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			c := NewRecordingClient()
+			c.Script("ReplyInThread", tt.script...)
+
+			messageID, err := c.ReplyInThread(context.Background(), "g1", "t1", seatalkbot.TextMessage("abc", ""))
+
+			tt.checkError(t, err)
+			assert.Equal(t, tt.wantID, messageID)
+			assert.Equal(t, 1, c.Calls("ReplyInThread"))
+
+			messages := c.Messages()
+			require.Len(t, messages, tt.wantMessages)
+			if tt.wantMessages > 0 {
+				assert.Equal(t, ReceivedMessage{
+					MessageID: tt.wantID,
+					GroupID:   "g1",
+					ThreadID:  "t1",
+					Tag:       "text",
+					Text:      "abc",
+					Raw:       []byte(`{"tag":"text","text":{"content":"abc"}}`),
+				}, messages[0])
+			}
+		})
+	}
+}
+
+func Test_RecordingClient_Broadcast(t *testing.T) {
+	t.Parallel()
+	errSend := errors.New("send failed")
+
+	c := NewRecordingClient()
+	c.Script("SendPrivateMessage", Response{}, Response{Err: errSend})
+
+	report, err := c.Broadcast(context.Background(), []seatalkbot.Recipient{
+		seatalkbot.EmployeeRecipient("e1"),
+		seatalkbot.EmployeeRecipient("e2"),
+		seatalkbot.GroupRecipient("g1"),
+	}, seatalkbot.TextMessage("hello", ""), seatalkbot.BroadcastOptions{})
+
+	require.NoError(t, err)
+	require.Len(t, report.Failed(), 1)
+	assert.Equal(t, seatalkbot.EmployeeRecipient("e2"), report.Failed()[0].Recipient)
+	assert.ErrorIs(t, report.Failed()[0].Err, errSend)
+
+	c.AssertEmployeeReceivedText(t, "e1", "hello")
+	c.AssertGroupReceivedText(t, "g1", "hello")
+}
+
+func Test_RecordingClient_scripted(t *testing.T) {
+	t.Parallel()
+	c := NewRecordingClient()
+	c.Script("GetGroupIDs", Response{GroupIDs: []string{"g1", "g2"}}, Response{Err: errors.New("failed")})
+
+	groupIDs, err := c.GetGroupIDs(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"g1", "g2"}, groupIDs)
+
+	_, err = c.GetGroupIDs(context.Background())
+	require.Error(t, err)
+
+	groupIDs, err = c.GetGroupIDs(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, groupIDs)
+	assert.Equal(t, 3, c.Calls("GetGroupIDs"))
+}
+
+func Test_MockClient(t *testing.T) {
+	t.Parallel()
+	var got string
+	m := &MockClient{
+		SendGroupMessageFunc: func(_ context.Context, groupID string, _ seatalkbot.Message) (string, error) {
+			got = groupID
+			return "abc", nil
+		},
+	}
+
+	messageID, err := m.SendGroupMessage(context.Background(), "g1", seatalkbot.TextMessage("abc", ""))
+	require.NoError(t, err)
+	assert.Equal(t, "abc", messageID)
+	assert.Equal(t, "g1", got)
+
+	assert.NoError(t, m.SendPrivateMessage(context.Background(), "e1", seatalkbot.TextMessage("abc", "")))
+}
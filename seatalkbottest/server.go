@@ -0,0 +1,476 @@
+// Package seatalkbottest provides utilities to test the code using seatalkbot without calling seatalk.
+package seatalkbottest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/tidwall/gjson"
+
+	"github.com/anandawira/seatalkbot"
+)
+
+// tokenLifetime is the lifetime of the access tokens issued by the Server.
+const tokenLifetime = 7200 * time.Second
+
+// ReceivedMessage is a message received by the Server or recorded by the RecordingClient.
+type ReceivedMessage struct {
+	// MessageID is the id assigned by the Server or the RecordingClient.
+	MessageID string
+	// EmployeeCode is set for the private messages.
+	EmployeeCode string
+	// GroupID is set for the group messages.
+	GroupID  string
+	ThreadID string
+	// Tag is the type of the message, e.g. text or interactive_message.
+	Tag string
+	// Text is the content of a text message.
+	Text string
+	// Raw is the message as sent by the client.
+	Raw json.RawMessage
+}
+
+// Fault is an error returned by the Server instead of handling the request.
+type Fault struct {
+	// StatusCode is the http status code, it's 200 by default.
+	StatusCode int
+	// Code is the code in the response body.
+	Code int
+	// RetryAfter is set as the Retry-After header when not zero.
+	RetryAfter time.Duration
+}
+
+// Employee is an employee known by the Server, used by the employee lookup endpoints.
+type Employee struct {
+	EmployeeCode string
+	Email        string
+	Mobile       string
+	Status       seatalkbot.EmployeeStatus
+}
+
+// Server is a fake seatalk server built on httptest. It implements the auth, single chat, group chat, joined groups,
+// group info and employee lookup endpoints, and records every message it receives.
+// It is safe to use it from multiple goroutines.
+type Server struct {
+	*httptest.Server
+
+	mu          sync.Mutex
+	tokens      map[string]time.Time
+	issued      int
+	messages    []ReceivedMessage
+	groups      map[string]group
+	employees   []Employee
+	faults      map[string][]Fault
+	requests    map[string]int
+	credentials *[2]string
+}
+
+type group struct {
+	name    string
+	members []string
+}
+
+// NewServer starts and returns a Server. The caller should call Close when finished, to shut it down.
+func NewServer() *Server {
+	s := &Server{
+		tokens:   make(map[string]time.Time),
+		groups:   make(map[string]group),
+		faults:   make(map[string][]Fault),
+		requests: make(map[string]int),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/auth/app_access_token", s.handleAccessToken)
+	mux.HandleFunc("/messaging/v2/single_chat", s.authenticated(s.handleSingleChat))
+	mux.HandleFunc("/messaging/v2/group_chat", s.authenticated(s.handleGroupChat))
+	mux.HandleFunc("/messaging/v2/update", s.authenticated(s.handleUpdate))
+	mux.HandleFunc("/messaging/v2/group_chat/joined", s.authenticated(s.handleJoinedGroups))
+	mux.HandleFunc("/messaging/v2/group_chat/info", s.authenticated(s.handleGroupInfo))
+	mux.HandleFunc("/messaging/v2/group_chat/members", s.authenticated(s.handleGroupMembers))
+	mux.HandleFunc("/contacts/v2/get_employee_code_with_email", s.authenticated(s.handleEmployeeLookup("emails")))
+	mux.HandleFunc("/contacts/v2/get_employee_code_with_mobile", s.authenticated(s.handleEmployeeLookup("mobiles")))
+
+	s.Server = httptest.NewServer(s.withFaults(mux))
+
+	return s
+}
+
+// RequireCredentials makes the auth endpoint reject the app id and app secret other than the ones provided.
+// By default, any credentials are accepted.
+func (s *Server) RequireCredentials(appID, appSecret string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.credentials = &[2]string{appID, appSecret}
+}
+
+// AddGroup makes the bot a member of the group. Sending a message to a group that is not added fails with
+// seatalkbot.CodeBotNotInGroup.
+func (s *Server) AddGroup(groupID, name string, memberEmployeeCodes ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.groups[groupID] = group{name: name, members: memberEmployeeCodes}
+}
+
+// AddEmployee adds an employee returned by the employee lookup endpoints.
+func (s *Server) AddEmployee(employee Employee) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.employees = append(s.employees, employee)
+}
+
+// FailNext makes the next request to the endpoint path, e.g. /messaging/v2/group_chat, fail with the fault.
+// The faults of the same path are returned in the order they're added, one per request.
+func (s *Server) FailNext(path string, fault Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.faults[path] = append(s.faults[path], fault)
+}
+
+// RateLimitNext makes the next request to the endpoint path fail with seatalkbot.CodeRateLimited.
+func (s *Server) RateLimitNext(path string, retryAfter time.Duration) {
+	s.FailNext(path, Fault{StatusCode: http.StatusTooManyRequests, Code: seatalkbot.CodeRateLimited, RetryAfter: retryAfter})
+}
+
+// ExpireTokens expires every access token issued so far, the requests using them fail with
+// seatalkbot.CodeAccessTokenInvalid.
+func (s *Server) ExpireTokens() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for token := range s.tokens {
+		s.tokens[token] = time.Time{}
+	}
+}
+
+// Messages returns the messages received so far, in the order they're received.
+func (s *Server) Messages() []ReceivedMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]ReceivedMessage(nil), s.messages...)
+}
+
+// Requests returns the number of requests received by the endpoint path, including the failed ones.
+func (s *Server) Requests(path string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.requests[path]
+}
+
+// AssertEmployeeReceivedText asserts that the employee received a text message with the content.
+func (s *Server) AssertEmployeeReceivedText(t testing.TB, employeeCode, text string) bool {
+	t.Helper()
+
+	return assertEmployeeReceivedText(t, s.Messages(), employeeCode, text)
+}
+
+// AssertGroupReceivedText asserts that the group received a text message with the content.
+func (s *Server) AssertGroupReceivedText(t testing.TB, groupID, text string) bool {
+	t.Helper()
+
+	return assertGroupReceivedText(t, s.Messages(), groupID, text)
+}
+
+func assertEmployeeReceivedText(t testing.TB, messages []ReceivedMessage, employeeCode, text string) bool {
+	t.Helper()
+
+	return assertReceived(t, messages, fmt.Sprintf("employee %s", employeeCode), text, func(m ReceivedMessage) bool {
+		return m.EmployeeCode == employeeCode
+	})
+}
+
+func assertGroupReceivedText(t testing.TB, messages []ReceivedMessage, groupID, text string) bool {
+	t.Helper()
+
+	return assertReceived(t, messages, fmt.Sprintf("group %s", groupID), text, func(m ReceivedMessage) bool {
+		return m.GroupID == groupID
+	})
+}
+
+func assertReceived(t testing.TB, messages []ReceivedMessage, recipient, text string, match func(m ReceivedMessage) bool) bool {
+	t.Helper()
+
+	var received []string
+	for _, m := range messages {
+		if !match(m) {
+			continue
+		}
+		if m.Tag == "text" && m.Text == text {
+			return true
+		}
+
+		received = append(received, fmt.Sprintf("%s: %q", m.Tag, m.Text))
+	}
+
+	t.Errorf("%s did not receive text %q, received: [%s]", recipient, text, strings.Join(received, ", "))
+	return false
+}
+
+// withFaults counts the requests and returns the next fault of the path, if any, instead of handling the request.
+func (s *Server) withFaults(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.requests[r.URL.Path]++
+
+		var fault *Fault
+		if faults := s.faults[r.URL.Path]; len(faults) > 0 {
+			fault = &faults[0]
+			s.faults[r.URL.Path] = faults[1:]
+		}
+		s.mu.Unlock()
+
+		if fault == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if fault.RetryAfter > 0 {
+			w.Header().Set("Retry-After", strconv.Itoa(int(fault.RetryAfter.Seconds())))
+		}
+
+		statusCode := fault.StatusCode
+		if statusCode == 0 {
+			statusCode = http.StatusOK
+		}
+
+		writeJSON(w, statusCode, map[string]any{"code": fault.Code})
+	})
+}
+
+// authenticated rejects the request with seatalkbot.CodeAccessTokenInvalid when its access token is not valid.
+func (s *Server) authenticated(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+
+		s.mu.Lock()
+		expiry, ok := s.tokens[token]
+		s.mu.Unlock()
+
+		if !ok || time.Now().After(expiry) {
+			writeCode(w, seatalkbot.CodeAccessTokenInvalid)
+			return
+		}
+
+		next(w, r)
+	}
+}
+
+func (s *Server) handleAccessToken(w http.ResponseWriter, r *http.Request) {
+	body := readBody(r)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.credentials != nil &&
+		(gjson.GetBytes(body, "app_id").String() != s.credentials[0] || gjson.GetBytes(body, "app_secret").String() != s.credentials[1]) {
+		writeCode(w, seatalkbot.CodePermissionDenied)
+		return
+	}
+
+	s.issued++
+	token := fmt.Sprintf("token-%d", s.issued)
+	expiry := time.Now().Add(tokenLifetime)
+	s.tokens[token] = expiry
+
+	writeJSON(w, http.StatusOK, map[string]any{"code": 0, "app_access_token": token, "expire": expiry.Unix()})
+}
+
+func (s *Server) handleSingleChat(w http.ResponseWriter, r *http.Request) {
+	body := readBody(r)
+
+	messageID := s.record(gjson.GetBytes(body, "message"), func(m *ReceivedMessage) {
+		m.EmployeeCode = gjson.GetBytes(body, "employee_code").String()
+	})
+
+	writeJSON(w, http.StatusOK, map[string]any{"code": 0, "message_id": messageID})
+}
+
+func (s *Server) handleGroupChat(w http.ResponseWriter, r *http.Request) {
+	body := readBody(r)
+	groupID := gjson.GetBytes(body, "group_id").String()
+
+	s.mu.Lock()
+	_, ok := s.groups[groupID]
+	s.mu.Unlock()
+
+	if !ok {
+		writeCode(w, seatalkbot.CodeBotNotInGroup)
+		return
+	}
+
+	messageID := s.record(gjson.GetBytes(body, "message"), func(m *ReceivedMessage) {
+		m.GroupID = groupID
+	})
+
+	writeJSON(w, http.StatusOK, map[string]any{"code": 0, "message_id": messageID})
+}
+
+func (s *Server) handleUpdate(w http.ResponseWriter, r *http.Request) {
+	body := readBody(r)
+	messageID := gjson.GetBytes(body, "message_id").String()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, m := range s.messages {
+		if m.MessageID == messageID {
+			s.messages[i].Raw = json.RawMessage(gjson.GetBytes(body, "message").Raw)
+			writeCode(w, 0)
+			return
+		}
+	}
+
+	writeCode(w, seatalkbot.CodeInvalidRequest)
+}
+
+func (s *Server) handleJoinedGroups(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	groupIDs := make([]string, 0, len(s.groups))
+	for groupID := range s.groups {
+		groupIDs = append(groupIDs, groupID)
+	}
+	s.mu.Unlock()
+
+	sort.Strings(groupIDs)
+	page, nextCursor := paginate(r, groupIDs)
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"code":               0,
+		"next_cursor":        nextCursor,
+		"joined_group_chats": map[string]any{"group_id": page},
+	})
+}
+
+func (s *Server) handleGroupInfo(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	g, ok := s.groups[r.URL.Query().Get("group_id")]
+	s.mu.Unlock()
+
+	if !ok {
+		writeCode(w, seatalkbot.CodeBotNotInGroup)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"code": 0,
+		"group": map[string]any{
+			"group_name":       g.name,
+			"group_user_total": len(g.members),
+			"group_settings":   map[string]any{"can_view_member_list": true},
+		},
+	})
+}
+
+func (s *Server) handleGroupMembers(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	g, ok := s.groups[r.URL.Query().Get("group_id")]
+	s.mu.Unlock()
+
+	if !ok {
+		writeCode(w, seatalkbot.CodeBotNotInGroup)
+		return
+	}
+
+	page, nextCursor := paginate(r, g.members)
+
+	members := make([]map[string]any, 0, len(page))
+	for _, employeeCode := range page {
+		members = append(members, map[string]any{"employee_code": employeeCode})
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"code": 0, "next_cursor": nextCursor, "members": members})
+}
+
+// handleEmployeeLookup resolves the emails or mobiles in the field of the request body.
+func (s *Server) handleEmployeeLookup(field string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		body := readBody(r)
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		var employees []map[string]any
+		for _, input := range gjson.GetBytes(body, field).Array() {
+			for _, e := range s.employees {
+				if (field == "emails" && strings.EqualFold(e.Email, input.String())) || (field == "mobiles" && e.Mobile == input.String()) {
+					employees = append(employees, map[string]any{
+						"email":           e.Email,
+						"mobile":          e.Mobile,
+						"employee_code":   e.EmployeeCode,
+						"employee_status": int(e.Status),
+					})
+				}
+			}
+		}
+
+		writeJSON(w, http.StatusOK, map[string]any{"code": 0, "employees": employees})
+	}
+}
+
+// record stores the message and returns its id.
+func (s *Server) record(message gjson.Result, set func(m *ReceivedMessage)) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	m := ReceivedMessage{
+		MessageID: fmt.Sprintf("message-%d", len(s.messages)+1),
+		ThreadID:  message.Get("thread_id").String(),
+		Tag:       message.Get("tag").String(),
+		Text:      message.Get("text.content").String(),
+		Raw:       json.RawMessage(message.Raw),
+	}
+	set(&m)
+
+	s.messages = append(s.messages, m)
+
+	return m.MessageID
+}
+
+// paginate returns the page of the items requested by the page_size and cursor query, the cursor being the index
+// of the first item of the page.
+func paginate(r *http.Request, items []string) (page []string, nextCursor string) {
+	pageSize, err := strconv.Atoi(r.URL.Query().Get("page_size"))
+	if err != nil || pageSize <= 0 {
+		pageSize = 50
+	}
+
+	start, _ := strconv.Atoi(r.URL.Query().Get("cursor"))
+	start = min(max(start, 0), len(items))
+	end := min(start+pageSize, len(items))
+
+	if end < len(items) {
+		nextCursor = strconv.Itoa(end)
+	}
+
+	return append([]string{}, items[start:end]...), nextCursor
+}
+
+func readBody(r *http.Request) []byte {
+	var body json.RawMessage
+	_ = json.NewDecoder(r.Body).Decode(&body)
+
+	return body
+}
+
+func writeCode(w http.ResponseWriter, code int) {
+	writeJSON(w, http.StatusOK, map[string]any{"code": code})
+}
+
+func writeJSON(w http.ResponseWriter, statusCode int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	_ = json.NewEncoder(w).Encode(body)
+}
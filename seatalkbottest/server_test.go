@@ -0,0 +1,189 @@
+package seatalkbottest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anandawira/seatalkbot"
+)
+
+func newTestClient(t *testing.T, s *Server, policy *seatalkbot.RetryPolicy) seatalkbot.Client {
+	t.Helper()
+
+	c, err := seatalkbot.NewClient(seatalkbot.Config{
+		HTTPClient:  &http.Client{},
+		Host:        s.URL,
+		AppID:       "app",
+		AppSecret:   "secret",
+		RetryPolicy: policy,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = c.Close() })
+
+	return c
+}
+
+func Test_Server_messages(t *testing.T) {
+	t.Parallel()
+	s := NewServer()
+	defer s.Close()
+
+	s.RequireCredentials("app", "secret")
+	s.AddGroup("g1", "Group 1", "e1", "e2")
+
+	c := newTestClient(t, s, nil)
+	ctx := context.Background()
+
+	require.NoError(t, c.SendPrivateMessage(ctx, "e1", seatalkbot.TextMessage("hello", "")))
+
+	messageID, err := c.ReplyInThread(ctx, "g1", "t1", seatalkbot.TextMessage("hi group", ""))
+	require.NoError(t, err)
+	assert.Equal(t, "message-2", messageID)
+
+	_, err = c.SendGroupMessage(ctx, "g2", seatalkbot.TextMessage("hi", ""))
+	assert.True(t, seatalkbot.IsBotNotInGroup(err))
+
+	s.AssertEmployeeReceivedText(t, "e1", "hello")
+	s.AssertGroupReceivedText(t, "g1", "hi group")
+
+	messages := s.Messages()
+	require.Len(t, messages, 2)
+	assert.Equal(t, "t1", messages[1].ThreadID)
+
+	groupIDs, err := c.GetGroupIDs(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"g1"}, groupIDs)
+
+	info, err := c.GetGroupInfo(ctx, "g1")
+	require.NoError(t, err)
+	assert.Equal(t, "Group 1", info.GroupName)
+	assert.Equal(t, 2, info.MemberCount)
+}
+
+// fakeT records the errors instead of failing the test.
+type fakeT struct {
+	testing.TB
+	errors []string
+}
+
+func (t *fakeT) Errorf(format string, args ...any) {
+	t.errors = append(t.errors, fmt.Sprintf(format, args...))
+}
+
+func Test_Server_AssertEmployeeReceivedText(t *testing.T) {
+	t.Parallel()
+	s := NewServer()
+	defer s.Close()
+
+	c := newTestClient(t, s, nil)
+	require.NoError(t, c.SendPrivateMessage(context.Background(), "e1", seatalkbot.TextMessage("hello", "")))
+
+	mockT := &fakeT{TB: t}
+	assert.True(t, s.AssertEmployeeReceivedText(mockT, "e1", "hello"))
+	assert.False(t, s.AssertEmployeeReceivedText(mockT, "e1", "bye"))
+	assert.False(t, s.AssertEmployeeReceivedText(mockT, "e2", "hello"))
+	assert.Equal(t, []string{
+		`employee e1 did not receive text "bye", received: [text: "hello"]`,
+		`employee e2 did not receive text "hello", received: []`,
+	}, mockT.errors)
+}
+
+func Test_Server_employees(t *testing.T) {
+	t.Parallel()
+	s := NewServer()
+	defer s.Close()
+
+	s.AddEmployee(Employee{EmployeeCode: "e1", Email: "a@example.com", Status: seatalkbot.EmployeeActive})
+
+	c := newTestClient(t, s, nil)
+
+	lookups, err := c.GetEmployeeCodesByEmail(context.Background(), []string{"A@example.com", "b@example.com"})
+
+	require.NoError(t, err)
+	assert.Equal(t, []seatalkbot.EmployeeLookup{
+		{Input: "A@example.com", EmployeeCode: "e1", Status: seatalkbot.EmployeeActive},
+		{Input: "b@example.com", Status: seatalkbot.EmployeeNotFound},
+	}, lookups)
+}
+
+func Test_Server_faults(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name         string
+		inject       func(s *Server)
+		checkError   require.ErrorAssertionFunc
+		wantRequests int
+	}{
+		{
+			name: "it should retry when rate limited",
+			inject: func(s *Server) {
+				s.RateLimitNext("/messaging/v2/single_chat", 0)
+			},
+			checkError:   require.NoError,
+			wantRequests: 2,
+		},
+		{
+			name: "it should refresh the access token when it is expired",
+			inject: func(s *Server) {
+				s.ExpireTokens()
+			},
+			checkError:   require.NoError,
+			wantRequests: 2,
+		},
+		{
+			name: "it should return the injected error",
+			inject: func(s *Server) {
+				s.FailNext("/messaging/v2/single_chat", Fault{Code: seatalkbot.CodeUserNotSubscriber})
+			},
+			checkError:   require.Error,
+			wantRequests: 1,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			s := NewServer()
+			defer s.Close()
+
+			c := newTestClient(t, s, &seatalkbot.RetryPolicy{
+				MaxAttempts:    3,
+				InitialBackoff: time.Millisecond,
+				MaxBackoff:     time.Millisecond,
+			})
+
+			tt.inject(s)
+
+			err := c.SendPrivateMessage(context.Background(), "e1", seatalkbot.TextMessage("hello", ""))
+
+			tt.checkError(t, err)
+			assert.Equal(t, tt.wantRequests, s.Requests("/messaging/v2/single_chat"))
+		})
+	}
+}
+
+func Test_Server_negativeCursor(t *testing.T) {
+	t.Parallel()
+	s := NewServer()
+	defer s.Close()
+
+	s.AddGroup("g1", "Group 1")
+
+	c := newTestClient(t, s, nil)
+
+	req, err := http.NewRequest(http.MethodGet, s.URL+"/messaging/v2/group_chat/joined?cursor=-1", http.NoBody)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer "+c.AccessToken())
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
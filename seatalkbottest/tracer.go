@@ -0,0 +1,101 @@
+package seatalkbottest
+
+import (
+	"context"
+	"sync"
+
+	"github.com/anandawira/seatalkbot"
+)
+
+var _ seatalkbot.Tracer = (*SpanRecorder)(nil)
+
+// RecordedSpan is a span recorded by the SpanRecorder.
+type RecordedSpan struct {
+	// ID is the position of the span in the recorded spans, starting from 1.
+	ID int
+	// ParentID is the ID of the parent span, 0 when the span has no parent.
+	ParentID   int
+	Name       string
+	Attributes map[string]any
+	Err        error
+	Ended      bool
+}
+
+// SpanRecorder implements seatalkbot.Tracer by recording the spans in memory.
+// It is safe to use it from multiple goroutines.
+type SpanRecorder struct {
+	mu    sync.Mutex
+	spans []RecordedSpan
+}
+
+type spanKey struct{}
+
+// NewSpanRecorder returns an empty SpanRecorder.
+func NewSpanRecorder() *SpanRecorder {
+	return &SpanRecorder{}
+}
+
+// Start implements seatalkbot.Tracer
+func (r *SpanRecorder) Start(ctx context.Context, name string) (context.Context, seatalkbot.Span) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	parentID, _ := ctx.Value(spanKey{}).(int)
+	id := len(r.spans) + 1
+
+	r.spans = append(r.spans, RecordedSpan{ID: id, ParentID: parentID, Name: name, Attributes: make(map[string]any)})
+
+	return context.WithValue(ctx, spanKey{}, id), recordingSpan{recorder: r, id: id}
+}
+
+// ContextWithSpan returns a copy of ctx carrying the span, so the spans started with it are its children.
+func (r *SpanRecorder) ContextWithSpan(ctx context.Context, span RecordedSpan) context.Context {
+	return context.WithValue(ctx, spanKey{}, span.ID)
+}
+
+// Spans returns the spans recorded so far, in the order they're started.
+func (r *SpanRecorder) Spans() []RecordedSpan {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	spans := make([]RecordedSpan, len(r.spans))
+	for i, span := range r.spans {
+		span.Attributes = make(map[string]any, len(r.spans[i].Attributes))
+		for key, value := range r.spans[i].Attributes {
+			span.Attributes[key] = value
+		}
+		spans[i] = span
+	}
+
+	return spans
+}
+
+type recordingSpan struct {
+	recorder *SpanRecorder
+	id       int
+}
+
+func (s recordingSpan) SetAttribute(key string, value any) {
+	s.update(func(span *RecordedSpan) {
+		span.Attributes[key] = value
+	})
+}
+
+func (s recordingSpan) RecordError(err error) {
+	s.update(func(span *RecordedSpan) {
+		span.Err = err
+	})
+}
+
+func (s recordingSpan) End() {
+	s.update(func(span *RecordedSpan) {
+		span.Ended = true
+	})
+}
+
+func (s recordingSpan) update(fn func(span *RecordedSpan)) {
+	s.recorder.mu.Lock()
+	defer s.recorder.mu.Unlock()
+
+	fn(&s.recorder.spans[s.id-1])
+}
@@ -0,0 +1,103 @@
+package seatalkbottest
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anandawira/seatalkbot"
+)
+
+func Test_SpanRecorder_client(t *testing.T) {
+	t.Parallel()
+	s := NewServer()
+	defer s.Close()
+
+	for i := 0; i < 60; i++ {
+		s.AddGroup(fmt.Sprintf("g%02d", i), "Group")
+	}
+	s.FailNext("/messaging/v2/single_chat", Fault{Code: seatalkbot.CodeUserNotFound})
+
+	recorder := NewSpanRecorder()
+
+	c, err := seatalkbot.NewClient(
+		seatalkbot.Config{AppID: "app", AppSecret: "secret"},
+		seatalkbot.WithHost(s.URL),
+		seatalkbot.WithTracer(recorder),
+	)
+	require.NoError(t, err)
+	defer c.Close()
+
+	ctx, parent := recorder.Start(context.Background(), "handler")
+	parent.End()
+
+	err = c.SendPrivateMessage(ctx, "e1", seatalkbot.TextMessage("hello", ""))
+	require.Error(t, err)
+
+	_, err = c.SendGroupMessage(ctx, "g00", seatalkbot.TextMessage("hello", ""))
+	require.NoError(t, err)
+
+	groupIDs, err := c.GetGroupIDs(ctx)
+	require.NoError(t, err)
+	require.Len(t, groupIDs, 60)
+
+	spans := recorder.Spans()
+	require.Len(t, spans, 6)
+
+	assert.Equal(t, "seatalkbot.RefreshAccessToken", spans[0].Name)
+	assert.Equal(t, 0, spans[0].ParentID)
+
+	assert.Equal(t, "seatalkbot.SendPrivateMessage", spans[2].Name)
+	assert.Equal(t, parent.(recordingSpan).id, spans[2].ParentID)
+	assert.Equal(t, map[string]any{
+		seatalkbot.AttributeEndpoint:     "/messaging/v2/single_chat",
+		seatalkbot.AttributeEmployeeCode: "e1",
+		seatalkbot.AttributeErrorCode:    seatalkbot.CodeUserNotFound,
+	}, spans[2].Attributes)
+	assert.True(t, spans[2].Ended)
+	assert.True(t, seatalkbot.IsUserNotFound(spans[2].Err))
+
+	assert.Equal(t, "seatalkbot.SendGroupMessage", spans[3].Name)
+	assert.Equal(t, "g00", spans[3].Attributes[seatalkbot.AttributeGroupID])
+	assert.NoError(t, spans[3].Err)
+
+	for _, span := range spans[4:] {
+		assert.Equal(t, "seatalkbot.GetGroupIDs.page", span.Name)
+		assert.Equal(t, parent.(recordingSpan).id, span.ParentID)
+		assert.True(t, span.Ended)
+	}
+}
+
+func Test_SpanRecorder_pseudonymisedRecipients(t *testing.T) {
+	t.Parallel()
+	s := NewServer()
+	defer s.Close()
+
+	recorder := NewSpanRecorder()
+
+	c, err := seatalkbot.NewClient(seatalkbot.Config{
+		HTTPClient: &http.Client{},
+		Host:       s.URL,
+		AppID:      "app",
+		AppSecret:  "secret",
+		Tracer:     recorder,
+	}, seatalkbot.WithTracedRecipientHashKey([]byte("key")))
+	require.NoError(t, err)
+	defer c.Close()
+
+	require.NoError(t, c.SendPrivateMessage(context.Background(), "e1", seatalkbot.TextMessage("hello", "")))
+
+	spans := recorder.Spans()
+	require.Len(t, spans, 2)
+
+	mac := hmac.New(sha256.New, []byte("key"))
+	mac.Write([]byte("e1"))
+	assert.Equal(t, hex.EncodeToString(mac.Sum(nil)[:8]), spans[1].Attributes[seatalkbot.AttributeEmployeeCode])
+}
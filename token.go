@@ -0,0 +1,183 @@
+package seatalkbot
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// defaultTokenLifetime is the lifetime of an access token when the expiry is not returned by seatalk.
+	defaultTokenLifetime = 7200 * time.Second
+	// tokenRefreshMargin is how long before the expiry the access token is refreshed.
+	tokenRefreshMargin = 200 * time.Second
+	// tokenRetryInterval is the interval between the scheduled refreshes when the refresh fails.
+	tokenRetryInterval = 10 * time.Second
+	// tokenRefreshTimeout is the timeout of a fetch of the access token from the source.
+	tokenRefreshTimeout = 30 * time.Second
+)
+
+// tokenManager stores the access token so it can be read and refreshed concurrently.
+// Concurrent refreshes are coalesced into a single fetch.
+type tokenManager struct {
+	source  TokenSource
+	logger  *slog.Logger
+	metrics Metrics
+	tracer  Tracer
+	onError func(err error, health Health)
+
+	current atomic.Pointer[Token]
+	// refreshed is signaled every time a new access token is stored, so the scheduler can reschedule its refresh.
+	refreshed chan struct{}
+
+	mu         sync.Mutex
+	refreshing *tokenRefresh
+	status     Health
+}
+
+// tokenRefresh is an in-flight refresh, done is closed when it's finished.
+type tokenRefresh struct {
+	done chan struct{}
+	err  error
+}
+
+func newTokenManager(source TokenSource, logger *slog.Logger, metrics Metrics, tracer Tracer, onError func(err error, health Health)) *tokenManager {
+	m := &tokenManager{
+		source:    source,
+		logger:    logger,
+		metrics:   metrics,
+		tracer:    tracer,
+		onError:   onError,
+		refreshed: make(chan struct{}, 1),
+	}
+	m.current.Store(&Token{})
+
+	return m
+}
+
+// token returns the current access token, it's empty before the first successful refresh.
+func (m *tokenManager) token() string {
+	return m.current.Load().AccessToken
+}
+
+// expired reports whether there is no access token or it has expired.
+func (m *tokenManager) expired() bool {
+	current := m.current.Load()
+	return current.AccessToken == "" || !time.Now().Before(current.Expiry)
+}
+
+// health returns the status of the access token refreshes.
+func (m *tokenManager) health() Health {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.status
+}
+
+// refreshIn returns the duration until the access token should be refreshed. It's 0 when there is no access token.
+func (m *tokenManager) refreshIn() time.Duration {
+	expiry := m.current.Load().Expiry
+	if expiry.IsZero() {
+		return 0
+	}
+
+	return max(time.Until(expiry)-tokenRefreshMargin, 0)
+}
+
+// refresh gets a new access token from the source. When a refresh is already in flight, it waits for that
+// refresh instead.
+func (m *tokenManager) refresh(ctx context.Context) error {
+	return m.refreshToken(ctx, nil)
+}
+
+// refreshRejected refreshes the access token after the rejected token is refused by seatalk. Nothing is fetched
+// when the access token has already been replaced since the rejected token was used. When the refresh fails,
+// the rejected token is dropped, so the client has no access token until a refresh succeeds.
+func (m *tokenManager) refreshRejected(ctx context.Context, rejected string) error {
+	return m.refreshToken(ctx, &rejected)
+}
+
+// refreshToken gets a new access token from the source. When rejected is not nil, the source is told about
+// the rejected token, and nothing is fetched if the current access token is not the rejected one anymore.
+// The fetch is shared by the concurrent callers, so it's not cancelled with ctx: a caller whose ctx is done
+// stops waiting for it, and the others still get its result.
+func (m *tokenManager) refreshToken(ctx context.Context, rejected *string) error {
+	m.mu.Lock()
+	call := m.refreshing
+	if call == nil {
+		if rejected != nil && m.token() != "" && m.token() != *rejected {
+			m.mu.Unlock()
+			return nil
+		}
+
+		call = &tokenRefresh{done: make(chan struct{})}
+		m.refreshing = call
+		go m.fetch(context.WithoutCancel(ctx), call, rejected)
+	}
+	m.mu.Unlock()
+
+	select {
+	case <-call.done:
+		return call.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// fetch gets a new access token from the source within tokenRefreshTimeout, stores it and finishes the call.
+func (m *tokenManager) fetch(ctx context.Context, call *tokenRefresh, rejected *string) {
+	ctx, cancel := context.WithTimeout(ctx, tokenRefreshTimeout)
+	defer cancel()
+
+	if source, ok := m.source.(rejectionAwareTokenSource); ok && rejected != nil {
+		source.tokenRejected(*rejected)
+	}
+
+	ctx, span := m.tracer.Start(ctx, "seatalkbot.RefreshAccessToken")
+	start := time.Now()
+
+	token, err := m.source.Token(ctx)
+	endSpan(span, err)
+	latency := time.Since(start)
+
+	if err == nil {
+		token = withDefaultExpiry(token)
+		m.current.Store(&token)
+		select {
+		case m.refreshed <- struct{}{}:
+		default:
+		}
+		m.logger.InfoContext(ctx, "access token refreshed", slog.Any("token", token), slog.Duration("latency", latency))
+	} else {
+		m.logger.WarnContext(ctx, "can't refresh access token", slog.Any("error", err), slog.Duration("latency", latency))
+	}
+
+	m.metrics.ObserveTokenRefresh(TokenRefreshMetric{Duration: latency, Err: err})
+	call.err = err
+
+	m.mu.Lock()
+	m.refreshing = nil
+	if err == nil {
+		m.status.LastRefresh = time.Now()
+		m.status.Expiry = token.Expiry
+		m.status.ConsecutiveFailures = 0
+	} else {
+		m.status.ConsecutiveFailures++
+		m.status.LastError = err
+
+		// The rejected access token can't be used anymore, even if it has not expired.
+		if rejected != nil {
+			m.current.Store(&Token{})
+			m.status.Expiry = time.Time{}
+		}
+	}
+	health := m.status
+	m.mu.Unlock()
+	close(call.done)
+
+	if err != nil && m.onError != nil {
+		m.onError(err, health)
+	}
+}
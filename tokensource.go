@@ -0,0 +1,208 @@
+package seatalkbot
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Token is an app access token with its expiry.
+type Token struct {
+	AccessToken string    `json:"access_token"`
+	Expiry      time.Time `json:"expiry"`
+}
+
+// TokenSource provides the access token used by the client.
+type TokenSource interface {
+	// Token returns a valid access token. It's called when the client needs a new access token, i.e. on
+	// initialization, shortly before the current access token expires and when it's rejected by seatalk.
+	// The Expiry should be set to when the access token expires. When it's zero, the access token is assumed
+	// to expire after 7200 seconds, the lifetime of the access tokens issued by seatalk.
+	Token(ctx context.Context) (Token, error)
+}
+
+// withDefaultExpiry returns the token with the default lifetime when its Expiry is not set.
+func withDefaultExpiry(token Token) Token {
+	if token.Expiry.IsZero() {
+		token.Expiry = time.Now().Add(defaultTokenLifetime)
+	}
+
+	return token
+}
+
+// TokenSourceFunc is an adapter to allow the use of ordinary functions as TokenSource.
+type TokenSourceFunc func(ctx context.Context) (Token, error)
+
+// Token implements TokenSource
+func (f TokenSourceFunc) Token(ctx context.Context) (Token, error) {
+	return f(ctx)
+}
+
+// StaticTokenSource returns a TokenSource that always returns the accessToken. It never expires, so it's
+// mostly useful for tests.
+func StaticTokenSource(accessToken string) TokenSource {
+	return TokenSourceFunc(func(ctx context.Context) (Token, error) {
+		return Token{AccessToken: accessToken, Expiry: time.Now().Add(100 * 365 * 24 * time.Hour)}, nil
+	})
+}
+
+// rejectionAwareTokenSource is implemented by the token sources that need to know when their access token
+// is rejected by seatalk, so they don't return it again.
+type rejectionAwareTokenSource interface {
+	tokenRejected(accessToken string)
+}
+
+// TokenStore stores an access token so it can be shared. The implementation must be safe for concurrent use.
+type TokenStore interface {
+	// Load returns the stored access token, ok is false when there is no stored access token.
+	Load(ctx context.Context) (token Token, ok bool, err error)
+	// Save stores the access token, replacing the previous one.
+	Save(ctx context.Context, token Token) error
+}
+
+// NewCachedTokenSource returns a TokenSource that returns the access token in the store while it's not about to
+// expire. Otherwise, it gets a new access token from the source and saves it in the store.
+// The store is only a cache: when it can't be loaded, the access token is got from the source, and when it can't
+// be saved, the access token got from the source is returned anyway.
+func NewCachedTokenSource(source TokenSource, store TokenStore) TokenSource {
+	return newCachedTokenSource(source, store, slog.New(discardHandler{}))
+}
+
+// newCachedTokenSource is NewCachedTokenSource logging the errors of the store with the logger.
+func newCachedTokenSource(source TokenSource, store TokenStore, logger *slog.Logger) *cachedTokenSource {
+	return &cachedTokenSource{
+		source: source,
+		store:  store,
+		logger: logger,
+	}
+}
+
+type cachedTokenSource struct {
+	source TokenSource
+	store  TokenStore
+	logger *slog.Logger
+
+	// rejected is the last access token rejected by seatalk, it's ignored even if it's not expired.
+	rejected atomic.Value
+}
+
+// Token implements TokenSource
+func (s *cachedTokenSource) Token(ctx context.Context) (Token, error) {
+	token, ok, err := s.store.Load(ctx)
+	if err != nil {
+		s.logger.WarnContext(ctx, "can't load access token from store", slog.Any("error", err))
+	}
+
+	rejected, _ := s.rejected.Load().(string)
+	if err == nil && ok && token.AccessToken != rejected && time.Until(token.Expiry) > tokenRefreshMargin {
+		return token, nil
+	}
+
+	token, err = s.source.Token(ctx)
+	if err != nil {
+		return Token{}, err
+	}
+	token = withDefaultExpiry(token)
+
+	if err := s.store.Save(ctx, token); err != nil {
+		s.logger.WarnContext(ctx, "can't save access token to store", slog.Any("error", err))
+	}
+
+	return token, nil
+}
+
+func (s *cachedTokenSource) tokenRejected(accessToken string) {
+	s.rejected.Store(accessToken)
+}
+
+// NewMemoryTokenStore returns a TokenStore that keeps the access token in memory. It can be shared by the clients
+// of the same app in a process.
+func NewMemoryTokenStore() TokenStore {
+	return &memoryTokenStore{}
+}
+
+type memoryTokenStore struct {
+	mu    sync.RWMutex
+	token *Token
+}
+
+// Load implements TokenStore
+func (s *memoryTokenStore) Load(_ context.Context) (Token, bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.token == nil {
+		return Token{}, false, nil
+	}
+
+	return *s.token, true, nil
+}
+
+// Save implements TokenStore
+func (s *memoryTokenStore) Save(_ context.Context, token Token) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.token = &token
+
+	return nil
+}
+
+// NewFileTokenStore returns a TokenStore that keeps the access token as json in the file at path. It can be shared
+// by the processes running on the same host or mounting the same volume. The file is created on the first Save.
+func NewFileTokenStore(path string) TokenStore {
+	return &fileTokenStore{path: path}
+}
+
+type fileTokenStore struct {
+	path string
+}
+
+// Load implements TokenStore
+func (s *fileTokenStore) Load(_ context.Context) (Token, bool, error) {
+	b, err := os.ReadFile(s.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return Token{}, false, nil
+	}
+	if err != nil {
+		return Token{}, false, err
+	}
+
+	var token Token
+	if err := json.Unmarshal(b, &token); err != nil {
+		return Token{}, false, err
+	}
+
+	return token, true, nil
+}
+
+// Save implements TokenStore. The file is replaced atomically, so a concurrent Load never reads a partial file.
+func (s *fileTokenStore) Save(_ context.Context, token Token) error {
+	b, err := json.Marshal(token)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(f.Name(), s.path)
+}
@@ -0,0 +1,219 @@
+package seatalkbot
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_cachedTokenSource_Token(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name       string
+		stored     *Token
+		rejected   string
+		wantToken  string
+		wantSource int32
+	}{
+		{
+			name:       "it should get the access token from the source when the store is empty",
+			wantToken:  "fresh",
+			wantSource: 1,
+		},
+		{
+			name:       "it should return the stored access token when it's not about to expire",
+			stored:     &Token{AccessToken: "stored", Expiry: time.Now().Add(time.Hour)},
+			wantToken:  "stored",
+			wantSource: 0,
+		},
+		{
+			name:       "it should get the access token from the source when the stored one is about to expire",
+			stored:     &Token{AccessToken: "stored", Expiry: time.Now().Add(time.Second)},
+			wantToken:  "fresh",
+			wantSource: 1,
+		},
+		{
+			name:       "it should get the access token from the source when the stored one is rejected",
+			stored:     &Token{AccessToken: "stored", Expiry: time.Now().Add(time.Hour)},
+			rejected:   "stored",
+			wantToken:  "fresh",
+			wantSource: 1,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var calls atomic.Int32
+
+			store := NewMemoryTokenStore()
+			if tt.stored != nil {
+				require.NoError(t, store.Save(context.Background(), *tt.stored))
+			}
+
+			source := NewCachedTokenSource(TokenSourceFunc(func(ctx context.Context) (Token, error) {
+				calls.Add(1)
+				return Token{AccessToken: "fresh", Expiry: time.Now().Add(time.Hour)}, nil
+			}), store)
+			if tt.rejected != "" {
+				source.(rejectionAwareTokenSource).tokenRejected(tt.rejected)
+			}
+
+			token, err := source.Token(context.Background())
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantToken, token.AccessToken)
+			assert.Equal(t, tt.wantSource, calls.Load())
+
+			stored, ok, err := store.Load(context.Background())
+			require.NoError(t, err)
+			assert.True(t, ok)
+			assert.Equal(t, tt.wantToken, stored.AccessToken)
+		})
+	}
+}
+
+// failingTokenStore fails to load and save the access token.
+type failingTokenStore struct{}
+
+func (failingTokenStore) Load(context.Context) (Token, bool, error) {
+	return Token{}, false, errors.New("store is down")
+}
+
+func (failingTokenStore) Save(context.Context, Token) error {
+	return errors.New("store is down")
+}
+
+func Test_cachedTokenSource_Token_failingStore(t *testing.T) {
+	t.Parallel()
+	corruptPath := filepath.Join(t.TempDir(), "token.json")
+	require.NoError(t, os.WriteFile(corruptPath, []byte("{not json"), 0o600))
+
+	tests := []struct {
+		name  string
+		store TokenStore
+	}{
+		{
+			name:  "it should get the access token from the source when the store fails",
+			store: failingTokenStore{},
+		},
+		{
+			name:  "it should get the access token from the source when the stored file is corrupted",
+			store: NewFileTokenStore(corruptPath),
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			source := NewCachedTokenSource(TokenSourceFunc(func(ctx context.Context) (Token, error) {
+				return Token{AccessToken: "fresh", Expiry: time.Now().Add(time.Hour)}, nil
+			}), tt.store)
+
+			token, err := source.Token(context.Background())
+
+			require.NoError(t, err)
+			assert.Equal(t, "fresh", token.AccessToken)
+		})
+	}
+}
+
+func Test_NewClient_TokenStore_failing(t *testing.T) {
+	t.Parallel()
+	var logs strings.Builder
+
+	c, err := NewClient(Config{
+		TokenSource: StaticTokenSource("abc"),
+		TokenStore:  failingTokenStore{},
+		Logger:      slog.New(slog.NewTextHandler(&logs, nil)),
+	}, WithoutAutoRefresh())
+	require.NoError(t, err)
+	defer c.Close()
+
+	assert.Equal(t, "abc", c.AccessToken())
+	assert.Contains(t, logs.String(), "can't load access token from store")
+	assert.Contains(t, logs.String(), "can't save access token to store")
+}
+
+func Test_fileTokenStore(t *testing.T) {
+	t.Parallel()
+	store := NewFileTokenStore(filepath.Join(t.TempDir(), "token.json"))
+
+	_, ok, err := store.Load(context.Background())
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	want := Token{AccessToken: "abc", Expiry: time.Now().Add(time.Hour).Round(time.Second)}
+	require.NoError(t, store.Save(context.Background(), want))
+
+	got, ok, err := store.Load(context.Background())
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, want.AccessToken, got.AccessToken)
+	assert.True(t, want.Expiry.Equal(got.Expiry))
+}
+
+func Test_NewClient_TokenStore(t *testing.T) {
+	t.Parallel()
+	var issued atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		issued.Add(1)
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"app_access_token":"abc","expire":4102444800}`))
+	}))
+	defer server.Close()
+
+	store := NewMemoryTokenStore()
+	for i := 0; i < 3; i++ {
+		c, err := NewClient(Config{
+			HTTPClient: &http.Client{},
+			Host:       server.URL,
+			AppID:      "app-id",
+			AppSecret:  "app-secret",
+			TokenStore: store,
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, "abc", c.AccessToken())
+		require.NoError(t, c.Close())
+	}
+
+	assert.Equal(t, int32(1), issued.Load(), "clients sharing the store should fetch the access token once")
+}
+
+func Test_NewClient_TokenSource_zeroExpiry(t *testing.T) {
+	t.Parallel()
+	var calls atomic.Int32
+
+	source := TokenSourceFunc(func(ctx context.Context) (Token, error) {
+		calls.Add(1)
+		return Token{AccessToken: "abc"}, nil
+	})
+
+	for _, config := range []Config{
+		{TokenSource: source},
+		{TokenSource: source, TokenStore: NewMemoryTokenStore()},
+	} {
+		calls.Store(0)
+
+		c, err := NewClient(config)
+		require.NoError(t, err)
+
+		assert.WithinDuration(t, time.Now().Add(defaultTokenLifetime), c.Health().Expiry, time.Second)
+		assert.False(t, c.(*client).tokens.expired(), "it should not refetch the access token on every api call")
+		assert.Equal(t, int32(1), calls.Load())
+		require.NoError(t, c.Close())
+	}
+}
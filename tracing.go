@@ -0,0 +1,93 @@
+package seatalkbot
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+)
+
+// Span attributes set by the client.
+const (
+	AttributeEndpoint     = "seatalk.endpoint"
+	AttributeEmployeeCode = "seatalk.employee_code"
+	AttributeGroupID      = "seatalk.group_id"
+	AttributeErrorCode    = "seatalk.error_code"
+)
+
+// Tracer starts the spans of the client. It's small enough to be implemented on top of an OpenTelemetry
+// trace.Tracer:
+//
+//	func (t otelTracer) Start(ctx context.Context, name string) (context.Context, seatalkbot.Span) {
+//		ctx, span := t.tracer.Start(ctx, name)
+//		return ctx, otelSpan{span}
+//	}
+type Tracer interface {
+	// Start starts a span as a child of the span in ctx, if any, and returns a copy of ctx carrying the new span.
+	Start(ctx context.Context, name string) (context.Context, Span)
+}
+
+// Span is a span started by a Tracer.
+type Span interface {
+	// SetAttribute sets an attribute of the span, the value is either a string or an int.
+	SetAttribute(key string, value any)
+	// RecordError marks the span as failed with the error.
+	RecordError(err error)
+	// End ends the span.
+	End()
+}
+
+// noopTracer is the Tracer used when none is set in the config.
+type noopTracer struct{}
+
+func (noopTracer) Start(ctx context.Context, _ string) (context.Context, Span) {
+	return ctx, noopSpan{}
+}
+
+type noopSpan struct{}
+
+func (noopSpan) SetAttribute(string, any) {}
+func (noopSpan) RecordError(error)        {}
+func (noopSpan) End()                     {}
+
+// startSpan starts a span for the API call to the endpoint, with the recipient as attribute when it's not empty.
+func (c *client) startSpan(ctx context.Context, name, endpoint string, recipient Recipient) (context.Context, Span) {
+	ctx, span := c.tracer.Start(ctx, name)
+	span.SetAttribute(AttributeEndpoint, endpoint)
+
+	switch {
+	case recipient.EmployeeCode != "":
+		span.SetAttribute(AttributeEmployeeCode, c.traceValue(recipient.EmployeeCode))
+	case recipient.GroupID != "":
+		span.SetAttribute(AttributeGroupID, c.traceValue(recipient.GroupID))
+	}
+
+	return ctx, span
+}
+
+// traceValue returns the value as it should appear in the traces, pseudonymised when there is a hash key.
+func (c *client) traceValue(value string) string {
+	if len(c.tracedRecipientHashKey) == 0 {
+		return value
+	}
+
+	mac := hmac.New(sha256.New, c.tracedRecipientHashKey)
+	mac.Write([]byte(value))
+
+	return hex.EncodeToString(mac.Sum(nil)[:8])
+}
+
+// endSpan records the error, with its seatalk code, and ends the span.
+func endSpan(span Span, err error) {
+	if err != nil {
+		var apiErr *APIError
+		if errors.As(err, &apiErr) && apiErr.Code != 0 {
+			span.SetAttribute(AttributeErrorCode, apiErr.Code)
+		}
+
+		span.RecordError(err)
+	}
+
+	span.End()
+}